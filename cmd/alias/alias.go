@@ -0,0 +1,104 @@
+package alias
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aide-cloud/gvm/cmd"
+)
+
+func NewAliasCmd() *cobra.Command {
+	aliasCmd := &cobra.Command{
+		Use:   "alias",
+		Short: "Manage named aliases for Go versions",
+		Long: `Manage named aliases for Go versions.
+An alias either follows a selector (re-resolved on each use) or is frozen
+to the version the selector resolves to when the alias is set.
+Example:
+  gvm alias set prod 1.21.10 --freeze
+  gvm alias set edge latest
+  gvm alias ls
+  gvm alias rm edge
+  gvm use prod
+`,
+		Annotations: map[string]string{
+			"group": cmd.VersionCommands,
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return aliasFlags.ls(cmd.Context())
+		},
+	}
+	aliasCmd.AddCommand(newAliasSetCmd(), newAliasRmCmd(), newAliasLsCmd())
+	return aliasCmd
+}
+
+func newAliasSetCmd() *cobra.Command {
+	setCmd := &cobra.Command{
+		Use:   "set <name> <version>",
+		Short: "Create or update an alias",
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) != 2 {
+				return cmd.UsageError("please specify the alias name and the version")
+			}
+			return aliasFlags.set(c.Context(), args[0], args[1])
+		},
+	}
+	setCmd.Flags().BoolVar(&aliasFlags.isFreeze, "freeze", false, "Resolve the version now and freeze the alias to it")
+	return setCmd
+}
+
+func newAliasRmCmd() *cobra.Command {
+	rmCmd := &cobra.Command{
+		Use:   "rm <name>",
+		Short: "Remove an alias",
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.UsageError("please specify the alias name to remove")
+			}
+			return aliasFlags.rm(c.Context(), args[0])
+		},
+	}
+	return rmCmd
+}
+
+func newAliasLsCmd() *cobra.Command {
+	lsCmd := &cobra.Command{
+		Use:   "ls",
+		Short: "List out the aliases",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return aliasFlags.ls(cmd.Context())
+		},
+	}
+	return lsCmd
+}
+
+var aliasFlags = aliasCmdFlags{}
+
+type aliasCmdFlags struct {
+	isFreeze bool
+}
+
+func (a *aliasCmdFlags) set(ctx context.Context, name, selector string) error {
+	v, err := cmd.NewVersionManager(cmd.OptionsFrom(ctx))
+	if err != nil {
+		return err
+	}
+	return v.AliasSet(ctx, name, selector, a.isFreeze)
+}
+
+func (a *aliasCmdFlags) rm(ctx context.Context, name string) error {
+	v, err := cmd.NewVersionManager(cmd.OptionsFrom(ctx))
+	if err != nil {
+		return err
+	}
+	return v.AliasRm(ctx, name)
+}
+
+func (a *aliasCmdFlags) ls(ctx context.Context) error {
+	v, err := cmd.NewVersionManager(cmd.OptionsFrom(ctx))
+	if err != nil {
+		return err
+	}
+	return v.AliasLs(ctx)
+}
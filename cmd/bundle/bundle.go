@@ -0,0 +1,84 @@
+package bundle
+
+import (
+	"context"
+	"runtime"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aide-cloud/gvm/cmd"
+	"github.com/aide-cloud/gvm/internal/version"
+	"github.com/aide-cloud/gvm/pkg/dir"
+)
+
+func NewBundleCmd() *cobra.Command {
+	bundleCmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Create and import offline bundles for air-gapped machines",
+		Long: `Create and import offline bundles for air-gapped machines.
+Example:
+  gvm bundle create --versions 1.22.8,1.23.2 -o go-bundle.tar
+  gvm bundle import go-bundle.tar
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+	bundleCmd.AddCommand(newBundleCreateCmd(), newBundleImportCmd())
+	return bundleCmd
+}
+
+func newBundleCreateCmd() *cobra.Command {
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Package archives, an index and checksums into a tar file",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return bundleFlags.create(cmd.Context())
+		},
+	}
+	createCmd.Flags().StringVar(&bundleFlags.versions, "versions", "", "A version constraint such as '>=1.21', or a comma separated list of versions")
+	createCmd.Flags().StringVar(&bundleFlags.platforms, "platforms", runtime.GOOS+"/"+runtime.GOARCH, "Comma separated os/arch list")
+	createCmd.Flags().StringVarP(&bundleFlags.out, "out", "o", "go-bundle.tar", "The bundle file to write")
+	createCmd.MarkFlagRequired("versions")
+	return createCmd
+}
+
+func newBundleImportCmd() *cobra.Command {
+	importCmd := &cobra.Command{
+		Use:   "import <bundle>",
+		Short: "Load a bundle into the cache so that installs work without network",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return bundleFlags.importBundle(cmd.Context(), args[0])
+		},
+	}
+	return importCmd
+}
+
+var bundleFlags = bundleCmdFlags{}
+
+type bundleCmdFlags struct {
+	versions  string
+	platforms string
+	out       string
+}
+
+func (b *bundleCmdFlags) create(ctx context.Context) error {
+	platforms, err := version.ParsePlatforms(b.platforms)
+	if err != nil {
+		return cmd.UsageError("%v", err)
+	}
+	v, err := cmd.NewVersionManager(cmd.OptionsFrom(ctx))
+	if err != nil {
+		return err
+	}
+	return v.BundleCreate(ctx, b.versions, platforms, dir.ExpandHomeDir(b.out))
+}
+
+func (b *bundleCmdFlags) importBundle(ctx context.Context, bundlePath string) error {
+	v, err := cmd.NewVersionManager(cmd.OptionsFrom(ctx))
+	if err != nil {
+		return err
+	}
+	return v.BundleImport(ctx, dir.ExpandHomeDir(bundlePath))
+}
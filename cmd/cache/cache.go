@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aide-cloud/gvm/cmd"
+	"github.com/aide-cloud/gvm/internal/version"
+)
+
+func NewCacheCmd() *cobra.Command {
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the downloaded archive cache",
+		Long: `Manage the downloaded archive cache.
+Example:
+  gvm cache ls
+  gvm cache clean --older-than 30d --keep-installed
+  gvm cache verify
+  gvm cache path
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+	cacheCmd.AddCommand(newCacheLsCmd(), newCacheCleanCmd(), newCacheVerifyCmd(), newCachePathCmd())
+	return cacheCmd
+}
+
+func newCacheLsCmd() *cobra.Command {
+	lsCmd := &cobra.Command{
+		Use:   "ls",
+		Short: "List out the cached archives",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cacheFlags.ls(cmd.Context())
+		},
+	}
+	return lsCmd
+}
+
+func newCacheCleanCmd() *cobra.Command {
+	cleanCmd := &cobra.Command{
+		Use:   "clean",
+		Short: "Remove cached archives",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cacheFlags.clean(cmd.Context())
+		},
+	}
+	cleanCmd.Flags().StringVar(&cacheFlags.olderThan, "older-than", "", "Only remove archives older than the given age, e.g. 30d, 12h")
+	cleanCmd.Flags().BoolVar(&cacheFlags.isKeepInstalled, "keep-installed", false, "Keep archives of installed versions")
+	return cleanCmd
+}
+
+func newCacheVerifyCmd() *cobra.Command {
+	verifyCmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the SHA-256 of cached archives against the origin metadata",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cacheFlags.verify(cmd.Context())
+		},
+	}
+	return verifyCmd
+}
+
+func newCachePathCmd() *cobra.Command {
+	pathCmd := &cobra.Command{
+		Use:   "path",
+		Short: "Print the cache directory",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cacheFlags.path(cmd.Context())
+		},
+	}
+	return pathCmd
+}
+
+var cacheFlags = cacheCmdFlags{}
+
+type cacheCmdFlags struct {
+	olderThan       string
+	isKeepInstalled bool
+}
+
+func (c *cacheCmdFlags) ls(ctx context.Context) error {
+	v, err := cmd.NewVersionManager(cmd.OptionsFrom(ctx))
+	if err != nil {
+		return err
+	}
+	return v.CacheLs()
+}
+
+func (c *cacheCmdFlags) clean(ctx context.Context) error {
+	var olderThan time.Duration
+	if c.olderThan != "" {
+		d, err := version.ParseAge(c.olderThan)
+		if err != nil {
+			return cmd.UsageError("%v", err)
+		}
+		olderThan = d
+	}
+	v, err := cmd.NewVersionManager(cmd.OptionsFrom(ctx))
+	if err != nil {
+		return err
+	}
+	return v.CacheClean(ctx, olderThan, c.isKeepInstalled)
+}
+
+func (c *cacheCmdFlags) verify(ctx context.Context) error {
+	v, err := cmd.NewVersionManager(cmd.OptionsFrom(ctx))
+	if err != nil {
+		return err
+	}
+	return v.CacheVerify(ctx)
+}
+
+func (c *cacheCmdFlags) path(ctx context.Context) error {
+	v, err := cmd.NewVersionManager(cmd.OptionsFrom(ctx))
+	if err != nil {
+		return err
+	}
+	v.CachePath()
+	return nil
+}
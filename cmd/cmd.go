@@ -15,6 +15,10 @@ const (
 )
 
 func NewCmd() *cobra.Command {
+	options := &Options{}
+	// 配置文件决定参数的默认值，必须在注册参数前读取；解析失败时在执行子命令前返回错误
+	files, configErr := LoadConfigFiles()
+	options.Config = files
 	rootCmd := &cobra.Command{
 		Use:   "gvm",
 		Short: "A tool to manage Go versions",
@@ -23,9 +27,19 @@ examples and usage of using your application.`,
 		Run: func(cmd *cobra.Command, args []string) {
 			cmd.Help()
 		},
+		PersistentPreRunE: func(c *cobra.Command, args []string) error {
+			if configErr != nil {
+				return configErr
+			}
+			return withOptions(c, *options)
+		},
+		// 错误由 main 统一输出，避免 cobra 重复打印错误和用法
+		SilenceErrors: true,
+		SilenceUsage:  true,
 	}
 
-	InitFlags(rootCmd)
+	bindFlags(rootCmd, options, files)
+	rootCmd.SetFlagErrorFunc(flagError)
 
 	// Set custom help template to display commands in groups
 	rootCmd.SetHelpTemplate(customHelpTemplate)
@@ -0,0 +1,236 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aide-cloud/gvm/cmd"
+	configfile "github.com/aide-cloud/gvm/pkg/config"
+	"github.com/aide-cloud/gvm/pkg/log"
+	"github.com/aide-cloud/gvm/pkg/output"
+)
+
+func NewConfigCmd() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage the gvm configuration files",
+		Long: `Manage the gvm configuration files.
+Options are resolved in the order: flag > env > project config (.gvm.yaml in the
+current or a parent directory) > user config (~/.gvm/config.yaml, env: GVM_CONFIG) > default.
+Example:
+  gvm config list
+  gvm config get sdk-dir
+  gvm config set download-url https://mirrors.aliyun.com/golang/
+  gvm config set mirrors https://golang.google.cn/dl/ https://mirrors.ustc.edu.cn/golang/
+  gvm config set --project connections 4
+  gvm config unset download-url
+  gvm config path
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+	configCmd.AddCommand(newConfigListCmd(), newConfigGetCmd(), newConfigSetCmd(), newConfigUnsetCmd(), newConfigPathCmd())
+	return configCmd
+}
+
+func newConfigListCmd() *cobra.Command {
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List out the effective value of every option and where it came from",
+		RunE: func(c *cobra.Command, args []string) error {
+			return configFlags.list(c)
+		},
+	}
+	return listCmd
+}
+
+func newConfigGetCmd() *cobra.Command {
+	getCmd := &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the effective value of an option",
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.UsageError("please specify the option to get")
+			}
+			return configFlags.get(c, args[0])
+		},
+	}
+	return getCmd
+}
+
+func newConfigSetCmd() *cobra.Command {
+	setCmd := &cobra.Command{
+		Use:   "set <key> <value>...",
+		Short: "Write an option to the user or project config",
+		RunE: func(_ *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return cmd.UsageError("please specify the option and its value")
+			}
+			return configFlags.set(args[0], args[1:])
+		},
+	}
+	setCmd.Flags().BoolVar(&configFlags.isProject, "project", false, "Write to the project config instead of the user config")
+	return setCmd
+}
+
+func newConfigUnsetCmd() *cobra.Command {
+	unsetCmd := &cobra.Command{
+		Use:   "unset <key>",
+		Short: "Remove an option from the user or project config",
+		RunE: func(_ *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return cmd.UsageError("please specify the option to unset")
+			}
+			return configFlags.unset(args[0])
+		},
+	}
+	unsetCmd.Flags().BoolVar(&configFlags.isProject, "project", false, "Remove from the project config instead of the user config")
+	return unsetCmd
+}
+
+func newConfigPathCmd() *cobra.Command {
+	pathCmd := &cobra.Command{
+		Use:   "path",
+		Short: "Print the path of the user or project config",
+		RunE: func(_ *cobra.Command, args []string) error {
+			return configFlags.path()
+		},
+	}
+	pathCmd.Flags().BoolVar(&configFlags.isProject, "project", false, "Print the path of the project config")
+	return pathCmd
+}
+
+var configFlags = configCmdFlags{}
+
+type configCmdFlags struct {
+	isProject bool
+}
+
+func (cf *configCmdFlags) list(c *cobra.Command) error {
+	options := cmd.OptionsFrom(c.Context())
+	format, err := options.OutputFormat()
+	if err != nil {
+		return err
+	}
+	values := make([]cmd.SettingValue, 0, len(cmd.Settings))
+	for _, s := range cmd.Settings {
+		values = append(values, s.Resolve(c, options.Config))
+	}
+	if format.IsStructured() {
+		return output.Print(os.Stdout, format, values)
+	}
+	for _, v := range values {
+		fmt.Printf("%-22s %-40s %s\n", v.Key, v.Value, formatSource(v))
+	}
+	return nil
+}
+
+func (cf *configCmdFlags) get(c *cobra.Command, key string) error {
+	options := cmd.OptionsFrom(c.Context())
+	format, err := options.OutputFormat()
+	if err != nil {
+		return err
+	}
+	s, ok := cmd.LookupSetting(key)
+	if !ok {
+		return unknownKeyError(key)
+	}
+	value := s.Resolve(c, options.Config)
+	if format.IsStructured() {
+		return output.Print(os.Stdout, format, value)
+	}
+	fmt.Println(value.Value)
+	return nil
+}
+
+func (cf *configCmdFlags) set(key string, args []string) error {
+	s, ok := cmd.LookupSetting(key)
+	if !ok {
+		return unknownKeyError(key)
+	}
+	value, err := s.Parse(args)
+	if err != nil {
+		return err
+	}
+	if cf.isProject && s.IsUserOnly() {
+		return cmd.UsageError("%s can only be set in the user config", key)
+	}
+	file, err := cf.targetFile()
+	if err != nil {
+		return err
+	}
+	file.Set(key, value)
+	if err := file.Save(); err != nil {
+		return err
+	}
+	log.Info("set config", "key", key, "value", strings.Join(s.Redact(args), ","), "path", file.Path)
+	return nil
+}
+
+func (cf *configCmdFlags) unset(key string) error {
+	if _, ok := cmd.LookupSetting(key); !ok {
+		return unknownKeyError(key)
+	}
+	file, err := cf.targetFile()
+	if err != nil {
+		return err
+	}
+	if !file.Unset(key) {
+		return fmt.Errorf("%s is not set in %s", key, file.Path)
+	}
+	if err := file.Save(); err != nil {
+		return err
+	}
+	log.Info("unset config", "key", key, "path", file.Path)
+	return nil
+}
+
+func (cf *configCmdFlags) path() error {
+	if !cf.isProject {
+		fmt.Println(cmd.UserConfigPath())
+		return nil
+	}
+	path := cmd.ProjectConfigPath()
+	if path == "" {
+		return fmt.Errorf("no %s found in the current or a parent directory", cmd.ProjectConfigName)
+	}
+	fmt.Println(path)
+	return nil
+}
+
+// targetFile 读取要修改的配置文件，写入项目配置但还没有时在当前目录新建
+func (cf *configCmdFlags) targetFile() (*configfile.File, error) {
+	if !cf.isProject {
+		return configfile.Load(cmd.UserConfigPath())
+	}
+	path := cmd.ProjectConfigPath()
+	if path == "" {
+		workDir, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the current directory: %v", err)
+		}
+		path = filepath.Join(workDir, cmd.ProjectConfigName)
+	}
+	return configfile.Load(path)
+}
+
+// formatSource 来源及其环境变量名或配置文件路径，如 env GVM_SDK_DIR
+func formatSource(v cmd.SettingValue) string {
+	if v.Origin == "" {
+		return v.Source
+	}
+	return v.Source + " " + v.Origin
+}
+
+func unknownKeyError(key string) error {
+	keys := make([]string, 0, len(cmd.Settings))
+	for _, s := range cmd.Settings {
+		keys = append(keys, s.Key)
+	}
+	return cmd.UsageError("unknown option %q, must be one of %s", key, strings.Join(keys, ", "))
+}
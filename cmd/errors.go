@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aide-cloud/gvm/internal/version"
+	"github.com/aide-cloud/gvm/pkg/lock"
+)
+
+// ErrUsage 命令行参数错误
+var ErrUsage = errors.New("invalid usage")
+
+// 进程退出码，脚本可以按退出码区分失败原因
+const (
+	ExitOK               = 0
+	ExitError            = 1
+	ExitUsage            = 2
+	ExitVersionNotFound  = 3
+	ExitNotInstalled     = 4
+	ExitChecksumMismatch = 5
+	ExitUnsupportedShell = 6
+	ExitLockTimeout      = 7
+	// ExitInterrupted 被 Ctrl-C 或 SIGTERM 中断，与 shell 中 128+SIGINT 的约定一致
+	ExitInterrupted = 130
+)
+
+// UsageError 返回参数错误，对应退出码 ExitUsage
+func UsageError(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrUsage, fmt.Sprintf(format, args...))
+}
+
+// ExitCode 按错误类型返回进程退出码
+func ExitCode(err error) int {
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, ErrUsage):
+		return ExitUsage
+	case errors.Is(err, version.ErrVersionNotFound):
+		return ExitVersionNotFound
+	case errors.Is(err, version.ErrNotInstalled):
+		return ExitNotInstalled
+	case errors.Is(err, version.ErrChecksumMismatch):
+		return ExitChecksumMismatch
+	case errors.Is(err, version.ErrUnsupportedShell):
+		return ExitUnsupportedShell
+	case errors.Is(err, lock.ErrTimeout):
+		return ExitLockTimeout
+	case errors.Is(err, context.Canceled):
+		return ExitInterrupted
+	default:
+		return ExitError
+	}
+}
+
+// flagError 将参数解析错误标记为 ErrUsage
+func flagError(_ *cobra.Command, err error) error {
+	return fmt.Errorf("%w: %v", ErrUsage, err)
+}
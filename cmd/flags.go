@@ -1,48 +1,233 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/spf13/cobra"
 
 	"github.com/aide-cloud/gvm/internal/version"
-	"github.com/aide-cloud/gvm/pkg/env"
+	"github.com/aide-cloud/gvm/pkg/dir"
+	"github.com/aide-cloud/gvm/pkg/download"
 	"github.com/aide-cloud/gvm/pkg/log"
+	"github.com/aide-cloud/gvm/pkg/output"
 )
 
-var globalFlags = GlobalFlags{}
-
-type GlobalFlags struct {
+// Options 全局选项，由根命令的 persistent flags 解析，参数未指定时取环境变量、配置文件或默认值
+type Options struct {
 	OriginURL        string
 	DownloadURL      string
+	Mirrors          []string
 	CacheDir         string
 	SdkDir           string
 	VersionFilePath  string
 	LocalVersionFile string
+	OriginTTL        time.Duration
+	LockTimeout      time.Duration
+	NoCache          bool
+	Connections      int
+	Source           string
+	GoProxy          string
+	ArchiveDir       string
+	Shell            string
+
+	Proxy              string
+	CAFile             string
+	ClientCert         string
+	ClientKey          string
+	InsecureSkipVerify bool
+	Headers            []string
+	NoNetrc            bool
+
+	Verbose   bool
+	Quiet     bool
+	LogFormat string
+	LogFile   string
+
+	Eval   bool
+	Output string
+
+	// Config 参数默认值所取自的配置文件
+	Config ConfigFiles
+}
+
+type optionsKey struct{}
 
-	Eval bool
+// bindFlags 把全局选项注册为 c 的 persistent flags，所有子命令自动继承
+func bindFlags(c *cobra.Command, o *Options, files ConfigFiles) {
+	flags := c.PersistentFlags()
+	flags.StringVar(&o.OriginURL, "origin-url", settingString(files, "origin-url"), "The URL to fetch the origin versions, env: GVM_ORIGIN_URL")
+	flags.StringVar(&o.DownloadURL, "download-url", settingString(files, "download-url"), "The URL to download the sdk, env: GVM_DOWNLOAD_URL")
+	flags.StringSliceVar(&o.Mirrors, "mirrors", settingList(files, "mirrors"), "Fallback download URLs tried in order when --download-url is unreachable (go.dev source), env: GVM_MIRRORS")
+	flags.StringVar(&o.CacheDir, "cache-dir", settingString(files, "cache-dir"), "The directory to cache the origin versions, env: GVM_CACHE_DIR")
+	flags.StringVar(&o.SdkDir, "sdk-dir", settingString(files, "sdk-dir"), "The directory to store the sdk, env: GVM_SDK_DIR")
+	flags.StringVar(&o.VersionFilePath, "version-file-path", settingString(files, "version-file-path"), "The file path to store the versions, env: GVM_VERSION_FILE_PATH")
+	flags.StringVar(&o.LocalVersionFile, "local-version-file", settingString(files, "local-version-file"), "The file path to store the local versions, env: GVM_LOCAL_VERSION_FILE_PATH")
+	flags.DurationVar(&o.OriginTTL, "origin-ttl", settingDuration(files, "origin-ttl"), "How long the cached origin versions are used before revalidating, env: GVM_ORIGIN_TTL")
+	flags.DurationVar(&o.LockTimeout, "lock-timeout", settingDuration(files, "lock-timeout"), "How long to wait for another gvm process to release a lock, env: GVM_LOCK_TIMEOUT")
+	flags.BoolVar(&o.NoCache, "no-cache", settingBool(files, "no-cache"), "Stream downloads straight into the sdk directory without reading or writing the archive cache, env: GVM_NO_CACHE")
+	flags.IntVar(&o.Connections, "connections", settingInt(files, "connections"), "The number of concurrent connections used to download an archive, env: GVM_CONNECTIONS")
+	flags.StringVar(&o.Source, "source", settingString(files, "source"), "Where to fetch versions and archives from: go.dev, goproxy, mirror (a directory index at --download-url) or local (--archive-dir), env: GVM_SOURCE")
+	flags.StringVar(&o.GoProxy, "goproxy", settingString(files, "goproxy"), "The module proxy list used by --source goproxy, env: GOPROXY")
+	flags.StringVar(&o.ArchiveDir, "archive-dir", settingString(files, "archive-dir"), "The local directory of archives used by --source local, env: GVM_ARCHIVE_DIR")
+	flags.StringVar(&o.Shell, "shell", settingString(files, "shell"), "The shell whose config file gvm use writes GOROOT to: bash or zsh, defaults to $SHELL, env: GVM_SHELL")
+	flags.StringVar(&o.Proxy, "proxy", settingString(files, "proxy"), "The proxy URL for all requests, defaults to HTTP_PROXY/HTTPS_PROXY, env: GVM_PROXY")
+	flags.StringVar(&o.CAFile, "ca-file", settingString(files, "ca-file"), "An extra CA bundle (PEM) to trust, env: GVM_CA_FILE")
+	flags.StringVar(&o.ClientCert, "client-cert", settingString(files, "client-cert"), "The client certificate (PEM) for mutual TLS, env: GVM_CLIENT_CERT")
+	flags.StringVar(&o.ClientKey, "client-key", settingString(files, "client-key"), "The client private key (PEM) for mutual TLS, env: GVM_CLIENT_KEY")
+	flags.BoolVar(&o.InsecureSkipVerify, "insecure-skip-verify", settingBool(files, "insecure-skip-verify"), "Skip TLS certificate verification (insecure), env: GVM_INSECURE_SKIP_VERIFY")
+	flags.StringArrayVar(&o.Headers, "header", settingList(files, "header"), "An extra request header for a mirror host as HOST=NAME: VALUE, can be repeated")
+	flags.BoolVar(&o.NoNetrc, "no-netrc", settingBool(files, "no-netrc"), "Do not read credentials from $NETRC or ~/.netrc")
+	flags.BoolVarP(&o.Verbose, "verbose", "v", false, "Log every step, including debug messages")
+	flags.BoolVarP(&o.Quiet, "quiet", "q", false, "Only log warnings and errors")
+	flags.StringVar(&o.LogFormat, "log-format", settingString(files, "log-format"), "The format of logs written to stderr or --log-file: text or json, env: GVM_LOG_FORMAT")
+	flags.StringVar(&o.LogFile, "log-file", settingString(files, "log-file"), "Append logs to the file instead of stderr, env: GVM_LOG_FILE")
+	flags.BoolVar(&o.Eval, "eval", false, "Eval the command, only warnings and errors are logged")
+	flags.StringVar(&o.Output, "output", settingString(files, "output"), "The output format of results: table, json or yaml, env: GVM_OUTPUT")
 }
 
-func InitFlags(cmd *cobra.Command) {
-	cmd.Flags().StringVar(&globalFlags.OriginURL, "origin-url", env.GetEnv("GVM_ORIGIN_URL", "https://go.dev/dl/?mode=json&include=all"), "The URL to fetch the origin versions, env: GVM_ORIGIN_URL")
-	cmd.Flags().StringVar(&globalFlags.DownloadURL, "download-url", env.GetEnv("GVM_DOWNLOAD_URL", "https://dl.google.com/go/"), "The URL to download the sdk, env: GVM_DOWNLOAD_URL")
-	cmd.Flags().StringVar(&globalFlags.CacheDir, "cache-dir", env.GetEnv("GVM_CACHE_DIR", "~/.gvm/cache"), "The directory to cache the origin versions, env: GVM_CACHE_DIR")
-	cmd.Flags().StringVar(&globalFlags.SdkDir, "sdk-dir", env.GetEnv("GVM_SDK_DIR", "~/go/sdk"), "The directory to store the sdk, env: GVM_SDK_DIR")
-	cmd.Flags().StringVar(&globalFlags.VersionFilePath, "version-file-path", env.GetEnv("GVM_VERSION_FILE_PATH", "~/.gvm/versions.json"), "The file path to store the versions, env: GVM_VERSION_FILE_PATH")
-	cmd.Flags().StringVar(&globalFlags.LocalVersionFile, "local-version-file", env.GetEnv("GVM_LOCAL_VERSION_FILE_PATH", "~/.gvm/version"), "The file path to store the local versions, env: GVM_LOCAL_VERSION_FILE_PATH")
-	cmd.Flags().BoolVar(&globalFlags.Eval, "eval", false, "Eval the command")
+// withOptions 在执行子命令前按全局选项配置日志，并把选项放入命令的 context
+func withOptions(c *cobra.Command, o Options) error {
+	logConfig, err := o.logConfig()
+	if err != nil {
+		return err
+	}
+	if err := log.Setup(logConfig); err != nil {
+		return err
+	}
+	c.SetContext(context.WithValue(c.Context(), optionsKey{}, o))
+	return nil
 }
 
-func GetGlobalFlags() GlobalFlags {
-	log.SetPrintEnable(!globalFlags.Eval)
-	return globalFlags
+// logConfig 默认输出 Info 及以上级别的日志，--eval 时标准输出用于 eval，只保留警告和错误
+func (o Options) logConfig() (log.Config, error) {
+	if o.Verbose && o.Quiet {
+		return log.Config{}, UsageError("--verbose and --quiet cannot be used together")
+	}
+	if err := validateLogFormat(o.LogFormat); err != nil {
+		return log.Config{}, UsageError("%v", err)
+	}
+	c := log.Config{Level: slog.LevelInfo, Format: o.LogFormat, File: dir.ExpandHomeDir(o.LogFile)}
+	switch {
+	case o.Verbose:
+		c.Level = slog.LevelDebug
+	case o.Quiet, o.Eval:
+		c.Level = slog.LevelWarn
+	}
+	return c, nil
 }
 
-func NewVersionManager() *version.Version {
+// OptionsFrom 返回根命令解析的全局选项，不是由根命令执行时返回零值
+func OptionsFrom(ctx context.Context) Options {
+	o, _ := ctx.Value(optionsKey{}).(Options)
+	return o
+}
+
+// OutputFormat 校验并返回 --output 指定的输出格式
+func (o Options) OutputFormat() (output.Format, error) {
+	format, err := output.ParseFormat(o.Output)
+	if err != nil {
+		return "", UsageError("%v", err)
+	}
+	return format, nil
+}
+
+func NewVersionManager(o Options) (*version.Version, error) {
+	if !slices.Contains(version.SourceNames, o.Source) {
+		return nil, UsageError("unsupported source %q, must be one of %s", o.Source, strings.Join(version.SourceNames, ", "))
+	}
+	if o.Source == version.SourceLocal && o.ArchiveDir == "" {
+		return nil, UsageError("--archive-dir is required for source %s", version.SourceLocal)
+	}
+	outputFormat, err := o.OutputFormat()
+	if err != nil {
+		return nil, err
+	}
+	auth, err := newAuthConfig(o)
+	if err != nil {
+		return nil, err
+	}
+	httpClient, err := download.NewClient(download.ClientConfig{
+		ProxyURL:           o.Proxy,
+		CAFile:             o.CAFile,
+		CertFile:           o.ClientCert,
+		KeyFile:            o.ClientKey,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+		Auth:               auth,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http client: %v", err)
+	}
 	return version.NewVersion(
-		version.WithSdkDir(globalFlags.SdkDir),
-		version.WithCacheDir(globalFlags.CacheDir),
-		version.WithOriginURL(globalFlags.OriginURL),
-		version.WithDownloadURL(globalFlags.DownloadURL),
-		version.WithVersionFilePath(globalFlags.VersionFilePath),
-		version.WithLocalVersionFilePath(globalFlags.LocalVersionFile),
-	)
+		version.WithSdkDir(o.SdkDir),
+		version.WithCacheDir(o.CacheDir),
+		version.WithOriginURL(o.OriginURL),
+		version.WithDownloadURL(o.DownloadURL),
+		version.WithMirrors(o.Mirrors),
+		version.WithVersionFilePath(o.VersionFilePath),
+		version.WithLocalVersionFilePath(o.LocalVersionFile),
+		version.WithOriginTTL(o.OriginTTL),
+		version.WithLockTimeout(o.LockTimeout),
+		version.WithNoCache(o.NoCache),
+		version.WithConnections(o.Connections),
+		version.WithHTTPClient(httpClient),
+		version.WithSourceName(o.Source),
+		version.WithArchiveDir(o.ArchiveDir),
+		version.WithShell(o.Shell),
+		version.WithOutput(outputFormat),
+		version.WithGoProxyConfig(version.GoProxyConfig{
+			GoProxy:   o.GoProxy,
+			GoSumDB:   os.Getenv("GOSUMDB"),
+			GoNoSumDB: os.Getenv("GONOSUMDB"),
+			GoPrivate: os.Getenv("GOPRIVATE"),
+		}),
+	), nil
+}
+
+// newAuthConfig 镜像令牌只从环境变量 GVM_MIRROR_TOKEN 读取，避免出现在命令行历史中
+func newAuthConfig(o Options) (download.AuthConfig, error) {
+	auth := download.AuthConfig{Token: os.Getenv("GVM_MIRROR_TOKEN")}
+	mirrorURLs := append(configuredURLs("origin-url", o.OriginURL), configuredURLs("download-url", o.DownloadURL)...)
+	mirrorURLs = append(mirrorURLs, o.Mirrors...)
+	if o.Source == version.SourceGoProxy {
+		mirrorURLs = append(mirrorURLs, configuredURLs("goproxy", o.GoProxy)...)
+	}
+	for _, rawURL := range mirrorURLs {
+		if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+			auth.MirrorHosts = append(auth.MirrorHosts, u.Host)
+		}
+	}
+	for _, h := range o.Headers {
+		header, err := download.ParseHeader(h)
+		if err != nil {
+			return auth, err
+		}
+		auth.Headers = append(auth.Headers, header)
+	}
+	if !o.NoNetrc {
+		auth.NetrcPath = download.DefaultNetrcPath()
+	}
+	return auth, nil
+}
+
+// configuredURLs 返回用户配置的地址，去掉内置默认值中的地址，
+// 镜像令牌和 netrc 的 default 条目不会发给 go.dev、dl.google.com 和 proxy.golang.org
+func configuredURLs(key, value string) []string {
+	split := func(s string) []string {
+		return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '|' })
+	}
+	defaults := split(mustLookupSetting(key).Default)
+	var urls []string
+	for _, u := range split(value) {
+		if !slices.Contains(defaults, u) {
+			urls = append(urls, u)
+		}
+	}
+	return urls
 }
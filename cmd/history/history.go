@@ -0,0 +1,47 @@
+package history
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aide-cloud/gvm/cmd"
+)
+
+func NewHistoryCmd() *cobra.Command {
+	historyCmd := &cobra.Command{
+		Use:   "history",
+		Short: "List out the previous Go version switches",
+		Long: `List out the previous Go version switches, newest first.
+Example:
+  gvm history
+  gvm history -n 20
+`,
+		Annotations: map[string]string{
+			"group": cmd.VersionCommands,
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return historyFlags.history(cmd.Context())
+		},
+	}
+	historyFlags.initFlags(historyCmd)
+	return historyCmd
+}
+
+var historyFlags = historyCmdFlags{}
+
+type historyCmdFlags struct {
+	number int
+}
+
+func (h *historyCmdFlags) initFlags(c *cobra.Command) {
+	c.Flags().IntVarP(&h.number, "number", "n", 10, "The number of history entries to list")
+}
+
+func (h *historyCmdFlags) history(ctx context.Context) error {
+	v, err := cmd.NewVersionManager(cmd.OptionsFrom(ctx))
+	if err != nil {
+		return err
+	}
+	return v.History(h.number)
+}
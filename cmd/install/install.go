@@ -1,7 +1,11 @@
 package install
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -12,27 +16,34 @@ func NewInstallCmd() *cobra.Command {
 	installCmd := &cobra.Command{
 		Use:   "install",
 		Short: "Install a specific Go version",
-		Long: `Install a specific Go version.
+		Long: `Install one or more Go versions.
+Multiple versions are downloaded and installed concurrently.
 Example:
   gvm install 1.25.3
   gvm install latest
   gvm install -l
+  gvm install 1.21.13 1.22.8 1.23.2
+  gvm install --from-file versions.txt -j 2
 `,
 		Annotations: map[string]string{
 			"group": cmd.VersionCommands,
 		},
-		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) > 0 {
-				installFlags.version = args[0]
-			}
+		RunE: func(c *cobra.Command, args []string) error {
+			installFlags.versions = args
 			if installFlags.latest {
-				installFlags.version = "latest"
+				installFlags.versions = append(installFlags.versions, "latest")
+			}
+			if installFlags.fromFile != "" {
+				versions, err := readVersionsFile(installFlags.fromFile)
+				if err != nil {
+					return err
+				}
+				installFlags.versions = append(installFlags.versions, versions...)
 			}
-			if installFlags.version == "" {
-				fmt.Println("Please specify the version to install")
-				return
+			if len(installFlags.versions) == 0 {
+				return cmd.UsageError("please specify the version to install")
 			}
-			installFlags.install()
+			return installFlags.install(c.Context())
 		},
 	}
 	installFlags.initFlags(installCmd)
@@ -42,20 +53,49 @@ Example:
 var installFlags = installCmdFlags{}
 
 type installCmdFlags struct {
-	cmd.GlobalFlags
-	version string
-	latest  bool
-	isForce bool
+	versions []string
+	fromFile string
+	jobs     int
+	latest   bool
+	isForce  bool
 }
 
 func (i *installCmdFlags) initFlags(c *cobra.Command) {
-	cmd.InitFlags(c)
 	c.Flags().BoolVarP(&i.latest, "latest", "l", false, "Install the latest version")
 	c.Flags().BoolVarP(&i.isForce, "force", "f", false, "Force install the version")
+	c.Flags().StringVar(&i.fromFile, "from-file", "", "Read the versions to install from a file, one per line")
+	c.Flags().IntVarP(&i.jobs, "jobs", "j", 4, "The number of versions to install concurrently")
+}
+
+func (i *installCmdFlags) install(ctx context.Context) error {
+	v, err := cmd.NewVersionManager(cmd.OptionsFrom(ctx))
+	if err != nil {
+		return err
+	}
+	if len(i.versions) == 1 {
+		return v.Install(ctx, i.versions[0], i.isForce)
+	}
+	return v.InstallAll(ctx, i.versions, i.isForce, i.jobs)
 }
 
-func (i *installCmdFlags) install() {
-	i.GlobalFlags = cmd.GetGlobalFlags()
-	v := cmd.NewVersionManager()
-	v.Install(i.version, i.isForce)
+// readVersionsFile 读取版本列表文件，忽略空行和 # 开头的注释
+func readVersionsFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the versions file: %v", err)
+	}
+	defer file.Close()
+
+	var versions []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if line = strings.TrimSpace(line); line != "" {
+			versions = append(versions, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the versions file: %v", err)
+	}
+	return versions, nil
 }
@@ -1,6 +1,8 @@
 package list
 
 import (
+	"context"
+
 	"github.com/aide-cloud/gvm/cmd"
 	"github.com/spf13/cobra"
 )
@@ -18,8 +20,8 @@ Example:
 		Annotations: map[string]string{
 			"group": cmd.VersionCommands,
 		},
-		Run: func(cmd *cobra.Command, args []string) {
-			listFlags.versions()
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listFlags.versions(cmd.Context())
 		},
 	}
 
@@ -31,22 +33,22 @@ Example:
 var listFlags = listCmdFlags{}
 
 type listCmdFlags struct {
-	cmd.GlobalFlags
 	number      int
 	forceUpdate bool
 	latest      bool
 }
 
 func (l *listCmdFlags) initFlags(c *cobra.Command) {
-	cmd.InitFlags(c)
 	c.Flags().IntVarP(&l.number, "number", "n", 10, "The number of versions to list")
 	c.Flags().BoolVar(&l.forceUpdate, "force-update", false, "Force update the origin versions cache")
 	c.Flags().BoolVarP(&l.latest, "latest", "l", false, "Show the latest version only")
 
 }
 
-func (l *listCmdFlags) versions() {
-	l.GlobalFlags = cmd.GetGlobalFlags()
-	v := cmd.NewVersionManager()
-	v.List(l.latest, l.number, l.forceUpdate)
+func (l *listCmdFlags) versions(ctx context.Context) error {
+	v, err := cmd.NewVersionManager(cmd.OptionsFrom(ctx))
+	if err != nil {
+		return err
+	}
+	return v.List(ctx, l.latest, l.number, l.forceUpdate)
 }
@@ -1,6 +1,8 @@
 package ls
 
 import (
+	"context"
+
 	"github.com/spf13/cobra"
 
 	"github.com/aide-cloud/gvm/cmd"
@@ -17,26 +19,21 @@ Example:
 		Annotations: map[string]string{
 			"group": cmd.VersionCommands,
 		},
-		Run: func(cmd *cobra.Command, args []string) {
-			lsFlags.versions()
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return lsFlags.versions(cmd.Context())
 		},
 	}
-	lsFlags.initFlags(lsCmd)
 	return lsCmd
 }
 
 var lsFlags = lsCmdFlags{}
 
-type lsCmdFlags struct {
-	cmd.GlobalFlags
-}
+type lsCmdFlags struct{}
 
-func (l *lsCmdFlags) initFlags(c *cobra.Command) {
-	cmd.InitFlags(c)
-}
-
-func (l *lsCmdFlags) versions() {
-	l.GlobalFlags = cmd.GetGlobalFlags()
-	v := cmd.NewVersionManager()
-	v.Ls()
+func (l *lsCmdFlags) versions(ctx context.Context) error {
+	v, err := cmd.NewVersionManager(cmd.OptionsFrom(ctx))
+	if err != nil {
+		return err
+	}
+	return v.Ls(ctx)
 }
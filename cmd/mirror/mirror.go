@@ -0,0 +1,86 @@
+package mirror
+
+import (
+	"context"
+	"runtime"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aide-cloud/gvm/cmd"
+	"github.com/aide-cloud/gvm/internal/version"
+	"github.com/aide-cloud/gvm/pkg/dir"
+)
+
+func NewMirrorCmd() *cobra.Command {
+	mirrorCmd := &cobra.Command{
+		Use:   "mirror",
+		Short: "Serve the archive cache as a mirror for other gvm instances",
+		Long: `Serve the archive cache as a mirror for other gvm instances.
+Example:
+  gvm mirror serve --addr :8080
+  gvm mirror sync --versions '>=1.21' --platforms linux/amd64,darwin/arm64 --dest /srv/gomirror
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.Help()
+		},
+	}
+	mirrorCmd.AddCommand(newMirrorServeCmd(), newMirrorSyncCmd())
+	return mirrorCmd
+}
+
+func newMirrorServeCmd() *cobra.Command {
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve a go.dev compatible index and the cached archives over HTTP",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return mirrorFlags.serve(cmd.Context())
+		},
+	}
+	serveCmd.Flags().StringVar(&mirrorFlags.addr, "addr", ":8080", "The address to listen on")
+	return serveCmd
+}
+
+func newMirrorSyncCmd() *cobra.Command {
+	syncCmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Download archives into a mirror directory with a go.dev style index.json",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return mirrorFlags.sync(cmd.Context())
+		},
+	}
+	syncCmd.Flags().StringVar(&mirrorFlags.versions, "versions", "", "A version constraint such as '>=1.21', or a comma separated list of versions")
+	syncCmd.Flags().StringVar(&mirrorFlags.platforms, "platforms", runtime.GOOS+"/"+runtime.GOARCH, "Comma separated os/arch list")
+	syncCmd.Flags().StringVar(&mirrorFlags.dest, "dest", "", "The mirror directory")
+	syncCmd.MarkFlagRequired("versions")
+	syncCmd.MarkFlagRequired("dest")
+	return syncCmd
+}
+
+var mirrorFlags = mirrorCmdFlags{}
+
+type mirrorCmdFlags struct {
+	addr      string
+	versions  string
+	platforms string
+	dest      string
+}
+
+func (m *mirrorCmdFlags) serve(ctx context.Context) error {
+	v, err := cmd.NewVersionManager(cmd.OptionsFrom(ctx))
+	if err != nil {
+		return err
+	}
+	return v.MirrorServe(ctx, m.addr)
+}
+
+func (m *mirrorCmdFlags) sync(ctx context.Context) error {
+	platforms, err := version.ParsePlatforms(m.platforms)
+	if err != nil {
+		return cmd.UsageError("%v", err)
+	}
+	v, err := cmd.NewVersionManager(cmd.OptionsFrom(ctx))
+	if err != nil {
+		return err
+	}
+	return v.MirrorSync(ctx, m.versions, platforms, dir.ExpandHomeDir(m.dest))
+}
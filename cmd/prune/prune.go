@@ -0,0 +1,53 @@
+package prune
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aide-cloud/gvm/cmd"
+)
+
+func NewPruneCmd() *cobra.Command {
+	pruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove old Go versions and their cached archives",
+		Long: `Remove old Go versions and their cached archives.
+The active version and versions referenced by aliases are always kept.
+Only the current platform's archives of the removed versions are deleted.
+Example:
+  gvm prune --keep-latest-patch --keep 2
+  gvm prune --keep 3 --dry-run
+`,
+		Annotations: map[string]string{
+			"group": cmd.VersionCommands,
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return pruneFlags.prune(cmd.Context())
+		},
+	}
+	pruneFlags.initFlags(pruneCmd)
+	return pruneCmd
+}
+
+var pruneFlags = pruneCmdFlags{}
+
+type pruneCmdFlags struct {
+	keepLatestPatch bool
+	keep            int
+	isDryRun        bool
+}
+
+func (p *pruneCmdFlags) initFlags(c *cobra.Command) {
+	c.Flags().BoolVar(&p.keepLatestPatch, "keep-latest-patch", false, "Keep the newest patches of each minor line instead of the newest versions overall")
+	c.Flags().IntVar(&p.keep, "keep", 1, "The number of versions to keep")
+	c.Flags().BoolVar(&p.isDryRun, "dry-run", false, "Print the plan without removing anything")
+}
+
+func (p *pruneCmdFlags) prune(ctx context.Context) error {
+	v, err := cmd.NewVersionManager(cmd.OptionsFrom(ctx))
+	if err != nil {
+		return err
+	}
+	return v.Prune(ctx, p.keepLatestPatch, p.keep, p.isDryRun)
+}
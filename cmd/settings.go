@@ -0,0 +1,360 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/aide-cloud/gvm/internal/version"
+	"github.com/aide-cloud/gvm/pkg/config"
+	"github.com/aide-cloud/gvm/pkg/dir"
+	"github.com/aide-cloud/gvm/pkg/download"
+	"github.com/aide-cloud/gvm/pkg/env"
+	"github.com/aide-cloud/gvm/pkg/log"
+	"github.com/aide-cloud/gvm/pkg/output"
+)
+
+const (
+	// DefaultUserConfigPath 用户配置文件，可以通过环境变量 GVM_CONFIG 指定其他位置
+	DefaultUserConfigPath = "~/.gvm/config.yaml"
+	// ProjectConfigName 项目配置文件名，从当前目录开始逐级向上查找
+	ProjectConfigName = ".gvm.yaml"
+)
+
+// 选项生效值的来源，优先级从高到低
+const (
+	FromFlag    = "flag"
+	FromEnv     = "env"
+	FromProject = "project"
+	FromUser    = "user"
+	FromDefault = "default"
+)
+
+type settingKind int
+
+const (
+	kindString settingKind = iota
+	kindBool
+	kindInt
+	kindDuration
+	// kindList 字符串列表，环境变量中以逗号分隔
+	kindList
+)
+
+// Setting 可以通过参数、环境变量和配置文件设置的全局选项，Key 既是参数名也是配置文件中的键
+type Setting struct {
+	Key     string
+	Env     string
+	Default string
+	kind    settingKind
+	// userOnly 为 true 时忽略项目配置中的值，避免仓库中的配置修改下载地址、代理、目录、shell 和 TLS 等选项，
+	// 把镜像令牌发给其他主机或安装未经信任的来源提供的版本
+	userOnly bool
+	validate func(string) error
+	// redact 隐藏值中的凭据，用于输出和日志
+	redact func(string) string
+}
+
+// Settings 所有可以写入配置文件的选项
+var Settings = []Setting{
+	{Key: "origin-url", Env: "GVM_ORIGIN_URL", Default: version.DefaultOriginURL, userOnly: true, redact: download.RedactURL},
+	{Key: "download-url", Env: "GVM_DOWNLOAD_URL", Default: version.DefaultDownloadURL, userOnly: true, redact: download.RedactURL},
+	{Key: "mirrors", Env: "GVM_MIRRORS", kind: kindList, userOnly: true, redact: download.RedactURL},
+	{Key: "cache-dir", Env: "GVM_CACHE_DIR", Default: version.DefaultCacheDir, userOnly: true},
+	{Key: "sdk-dir", Env: "GVM_SDK_DIR", Default: version.DefaultSdkDir, userOnly: true},
+	{Key: "version-file-path", Env: "GVM_VERSION_FILE_PATH", Default: version.DefaultVersionFilePath, userOnly: true},
+	{Key: "local-version-file", Env: "GVM_LOCAL_VERSION_FILE_PATH", Default: version.DefaultLocalVersionFilePath, userOnly: true},
+	{Key: "origin-ttl", Env: "GVM_ORIGIN_TTL", Default: version.DefaultOriginTTL.String(), kind: kindDuration},
+	{Key: "lock-timeout", Env: "GVM_LOCK_TIMEOUT", Default: version.DefaultLockTimeout.String(), kind: kindDuration},
+	{Key: "no-cache", Env: "GVM_NO_CACHE", Default: "false", kind: kindBool},
+	{Key: "connections", Env: "GVM_CONNECTIONS", Default: "1", kind: kindInt},
+	{Key: "source", Env: "GVM_SOURCE", Default: version.SourceGoDev, validate: validateSource, userOnly: true},
+	{Key: "goproxy", Env: "GOPROXY", Default: "https://proxy.golang.org,direct", userOnly: true, redact: download.RedactURL},
+	{Key: "archive-dir", Env: "GVM_ARCHIVE_DIR", userOnly: true},
+	{Key: "shell", Env: "GVM_SHELL", userOnly: true},
+	{Key: "proxy", Env: "GVM_PROXY", userOnly: true, redact: download.RedactURL},
+	{Key: "ca-file", Env: "GVM_CA_FILE", userOnly: true},
+	{Key: "client-cert", Env: "GVM_CLIENT_CERT", userOnly: true},
+	{Key: "client-key", Env: "GVM_CLIENT_KEY", userOnly: true},
+	{Key: "insecure-skip-verify", Env: "GVM_INSECURE_SKIP_VERIFY", Default: "false", kind: kindBool, userOnly: true},
+	{Key: "header", kind: kindList, userOnly: true, redact: download.RedactHeader},
+	{Key: "no-netrc", Default: "false", kind: kindBool, userOnly: true},
+	{Key: "output", Env: "GVM_OUTPUT", Default: string(output.Table), validate: validateOutput},
+	{Key: "log-format", Env: "GVM_LOG_FORMAT", Default: log.FormatText, validate: validateLogFormat},
+	{Key: "log-file", Env: "GVM_LOG_FILE", userOnly: true},
+}
+
+// SettingValue 选项的生效值及其来源
+type SettingValue struct {
+	Key   string `json:"key" yaml:"key"`
+	Value string `json:"value" yaml:"value"`
+	// Source 为 flag、env、project、user 或 default
+	Source string `json:"source" yaml:"source"`
+	// Origin 来源为 env 时是环境变量名，为 project 或 user 时是配置文件路径
+	Origin string `json:"origin,omitempty" yaml:"origin,omitempty"`
+}
+
+// LookupSetting 按键查找选项
+func LookupSetting(key string) (Setting, bool) {
+	for _, s := range Settings {
+		if s.Key == key {
+			return s, true
+		}
+	}
+	return Setting{}, false
+}
+
+// Resolve 返回选项的生效值，优先级为 参数 > 环境变量 > 项目配置 > 用户配置 > 默认值，
+// 值中的凭据被隐藏，只用于展示；c 为 nil 时不检查参数
+func (s Setting) Resolve(c *cobra.Command, files ConfigFiles) SettingValue {
+	if c != nil {
+		if f := c.Flags().Lookup(s.Key); f != nil && f.Changed {
+			var value any = f.Value.String()
+			if list, ok := f.Value.(interface{ GetSlice() []string }); ok {
+				value = list.GetSlice()
+			}
+			return SettingValue{Key: s.Key, Value: s.format(s.redacted(value)), Source: FromFlag}
+		}
+	}
+	value, source, origin := s.lookup(files)
+	return SettingValue{Key: s.Key, Value: s.format(s.redacted(value)), Source: source, Origin: origin}
+}
+
+// Redact 隐藏各个值中的凭据，如请求头的值和 URL 中的密码
+func (s Setting) Redact(values []string) []string {
+	if s.redact == nil {
+		return values
+	}
+	redacted := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			value = s.redact(value)
+		}
+		redacted = append(redacted, value)
+	}
+	return redacted
+}
+
+func (s Setting) redacted(value any) any {
+	switch value := value.(type) {
+	case []string:
+		return s.Redact(value)
+	case string:
+		return s.Redact([]string{value})[0]
+	default:
+		return value
+	}
+}
+
+// lookup 按 环境变量 > 项目配置 > 用户配置 > 默认值 查找选项，列表返回 []string，其他返回 string
+func (s Setting) lookup(files ConfigFiles) (any, string, string) {
+	if s.Env != "" {
+		if value := os.Getenv(s.Env); value != "" {
+			return s.normalize(value), FromEnv, s.Env
+		}
+	}
+	if files.Project != nil && !s.userOnly {
+		if value, ok := files.Project.Get(s.Key); ok {
+			return s.normalize(value), FromProject, files.Project.Path
+		}
+	}
+	if files.User != nil {
+		if value, ok := files.User.Get(s.Key); ok {
+			return s.normalize(value), FromUser, files.User.Path
+		}
+	}
+	return s.normalize(s.Default), FromDefault, ""
+}
+
+// normalize 列表选项的字符串值按逗号拆分，非列表选项的列表值按逗号合并
+func (s Setting) normalize(value any) any {
+	switch value := value.(type) {
+	case string:
+		if s.kind == kindList {
+			return splitList(value)
+		}
+		return value
+	case []string:
+		if s.kind == kindList {
+			return value
+		}
+		return strings.Join(value, ",")
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func (s Setting) format(value any) string {
+	if list, ok := value.([]string); ok {
+		return strings.Join(list, ",")
+	}
+	return fmt.Sprint(value)
+}
+
+// IsUserOnly 是否只能在用户配置中设置
+func (s Setting) IsUserOnly() bool {
+	return s.userOnly
+}
+
+// Parse 校验命令行给出的值并转换为写入配置文件的类型，列表选项每个参数是一项
+func (s Setting) Parse(args []string) (any, error) {
+	if s.kind == kindList {
+		return args, nil
+	}
+	if len(args) != 1 {
+		return nil, UsageError("%s takes exactly one value", s.Key)
+	}
+	value := args[0]
+	switch s.kind {
+	case kindBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, UsageError("invalid value %q for %s, expected true or false", value, s.Key)
+		}
+		return b, nil
+	case kindInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, UsageError("invalid value %q for %s, expected an integer", value, s.Key)
+		}
+		return n, nil
+	case kindDuration:
+		if _, err := time.ParseDuration(value); err != nil {
+			return nil, UsageError("invalid value %q for %s, expected a duration such as 12h", value, s.Key)
+		}
+	}
+	if s.validate != nil {
+		if err := s.validate(value); err != nil {
+			return nil, UsageError("%v", err)
+		}
+	}
+	return value, nil
+}
+
+func validateSource(value string) error {
+	if slices.Contains(version.SourceNames, value) {
+		return nil
+	}
+	return fmt.Errorf("unsupported source %q, must be one of %s", value, strings.Join(version.SourceNames, ", "))
+}
+
+func validateOutput(value string) error {
+	_, err := output.ParseFormat(value)
+	return err
+}
+
+func validateLogFormat(value string) error {
+	if value == log.FormatText || value == log.FormatJSON {
+		return nil
+	}
+	return fmt.Errorf("unsupported log format %q, must be one of %s, %s", value, log.FormatText, log.FormatJSON)
+}
+
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// 以下函数返回不考虑参数时的生效值，用作参数的默认值，值无法解析时使用选项的默认值
+
+func settingString(files ConfigFiles, key string) string {
+	s := mustLookupSetting(key)
+	value, _, _ := s.lookup(files)
+	return s.format(value)
+}
+
+func settingList(files ConfigFiles, key string) []string {
+	s := mustLookupSetting(key)
+	value, _, _ := s.lookup(files)
+	list, _ := value.([]string)
+	return list
+}
+
+func settingBool(files ConfigFiles, key string) bool {
+	s := mustLookupSetting(key)
+	b, err := strconv.ParseBool(settingString(files, key))
+	if err != nil {
+		b, _ = strconv.ParseBool(s.Default)
+	}
+	return b
+}
+
+func settingInt(files ConfigFiles, key string) int {
+	s := mustLookupSetting(key)
+	n, err := strconv.Atoi(settingString(files, key))
+	if err != nil {
+		n, _ = strconv.Atoi(s.Default)
+	}
+	return n
+}
+
+func settingDuration(files ConfigFiles, key string) time.Duration {
+	s := mustLookupSetting(key)
+	d, err := time.ParseDuration(settingString(files, key))
+	if err != nil {
+		d, _ = time.ParseDuration(s.Default)
+	}
+	return d
+}
+
+func mustLookupSetting(key string) Setting {
+	s, ok := LookupSetting(key)
+	if !ok {
+		panic("unknown setting " + key)
+	}
+	return s
+}
+
+// ConfigFiles 用户配置和项目配置，没有项目配置时 Project 为 nil
+type ConfigFiles struct {
+	User    *config.File
+	Project *config.File
+}
+
+// LoadConfigFiles 读取用户配置和当前目录对应的项目配置，由根命令在注册参数前调用一次，
+// 无法解析的配置文件被忽略并返回错误
+func LoadConfigFiles() (ConfigFiles, error) {
+	var (
+		files    ConfigFiles
+		firstErr error
+	)
+	user, err := config.Load(UserConfigPath())
+	if err != nil {
+		firstErr = err
+	} else {
+		files.User = user
+	}
+	if path := ProjectConfigPath(); path != "" {
+		project, err := config.Load(path)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		} else if err == nil {
+			files.Project = project
+		}
+	}
+	return files, firstErr
+}
+
+// UserConfigPath 用户配置文件路径
+func UserConfigPath() string {
+	return dir.ExpandHomeDir(env.GetEnv("GVM_CONFIG", DefaultUserConfigPath))
+}
+
+// ProjectConfigPath 当前目录或上级目录中的项目配置文件，找不到时返回空
+func ProjectConfigPath() string {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	return config.Find(workDir, ProjectConfigName)
+}
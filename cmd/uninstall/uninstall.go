@@ -1,7 +1,7 @@
 package uninstall
 
 import (
-	"fmt"
+	"context"
 
 	"github.com/aide-cloud/gvm/cmd"
 	"github.com/spf13/cobra"
@@ -11,27 +11,29 @@ func NewUninstallCmd() *cobra.Command {
 	uninstallCmd := &cobra.Command{
 		Use:   "uninstall",
 		Short: "Uninstall a specific Go version",
-		Long: `Uninstall a specific Go version.
+		Long: `Uninstall one or more Go versions.
+Versions can be given as exact versions, aliases or constraint expressions
+matched against the installed versions. The active version is kept unless
+--force is given.
 Example:
   gvm uninstall go1.25.3
   gvm uninstall latest
   gvm uninstall -l
+  gvm uninstall go1.20.14 go1.21.13
+  gvm uninstall '<1.21' --yes
 `,
 		Annotations: map[string]string{
 			"group": cmd.VersionCommands,
 		},
-		Run: func(cmd *cobra.Command, args []string) {
-			if len(args) > 0 {
-				uninstallFlags.version = args[0]
-			}
+		RunE: func(c *cobra.Command, args []string) error {
+			uninstallFlags.versions = args
 			if uninstallFlags.latest {
-				uninstallFlags.version = "latest"
+				uninstallFlags.versions = append(uninstallFlags.versions, "latest")
 			}
-			if uninstallFlags.version == "" {
-				fmt.Println("Please specify the version to uninstall")
-				return
+			if len(uninstallFlags.versions) == 0 {
+				return cmd.UsageError("please specify the version to uninstall")
 			}
-			uninstallFlags.uninstall()
+			return uninstallFlags.uninstall(c.Context())
 		},
 	}
 	uninstallFlags.initFlags(uninstallCmd)
@@ -41,18 +43,22 @@ Example:
 var uninstallFlags = uninstallCmdFlags{}
 
 type uninstallCmdFlags struct {
-	cmd.GlobalFlags
-	version string
-	latest  bool
+	versions []string
+	latest   bool
+	isForce  bool
+	isYes    bool
 }
 
 func (u *uninstallCmdFlags) initFlags(c *cobra.Command) {
-	cmd.InitFlags(c)
-	c.Flags().BoolVarP(&u.latest, "latest", "l", false, "Uninstall the latest version")
+	c.Flags().BoolVarP(&u.latest, "latest", "l", false, "Uninstall the latest installed version")
+	c.Flags().BoolVarP(&u.isForce, "force", "f", false, "Allow uninstalling the active version")
+	c.Flags().BoolVarP(&u.isYes, "yes", "y", false, "Do not ask for confirmation")
 }
 
-func (u *uninstallCmdFlags) uninstall() {
-	u.GlobalFlags = cmd.GetGlobalFlags()
-	v := cmd.NewVersionManager()
-	v.Uninstall(u.version)
+func (u *uninstallCmdFlags) uninstall(ctx context.Context) error {
+	v, err := cmd.NewVersionManager(cmd.OptionsFrom(ctx))
+	if err != nil {
+		return err
+	}
+	return v.Uninstall(ctx, u.versions, u.isForce, u.isYes)
 }
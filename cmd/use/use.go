@@ -1,7 +1,7 @@
 package use
 
 import (
-	"fmt"
+	"context"
 
 	"github.com/aide-cloud/gvm/cmd"
 	"github.com/spf13/cobra"
@@ -16,11 +16,12 @@ Example:
   gvm use go1.25.3
   gvm use latest
   gvm use -l
+  gvm use -    # switch back to the previous version
 `,
 		Annotations: map[string]string{
 			"group": cmd.VersionCommands,
 		},
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(c *cobra.Command, args []string) error {
 			if len(args) > 0 {
 				useFlags.version = args[0]
 			}
@@ -28,11 +29,10 @@ Example:
 				useFlags.version = "latest"
 			}
 			if useFlags.version == "" {
-				fmt.Println("Please specify the version to use")
-				return
+				return cmd.UsageError("please specify the version to use")
 			}
 
-			useFlags.use()
+			return useFlags.use(c.Context())
 		},
 	}
 	useFlags.initFlags(useCmd)
@@ -42,20 +42,21 @@ Example:
 var useFlags = useCmdFlags{}
 
 type useCmdFlags struct {
-	cmd.GlobalFlags
 	version string
 	latest  bool
 	isForce bool
 }
 
 func (u *useCmdFlags) initFlags(c *cobra.Command) {
-	cmd.InitFlags(c)
 	c.Flags().BoolVarP(&u.latest, "latest", "l", false, "Use the latest version")
 	c.Flags().BoolVarP(&u.isForce, "force", "f", false, "Force use the version")
 }
 
-func (u *useCmdFlags) use() {
-	u.GlobalFlags = cmd.GetGlobalFlags()
-	v := cmd.NewVersionManager()
-	v.Use(u.version, u.isForce, u.Eval)
+func (u *useCmdFlags) use(ctx context.Context) error {
+	options := cmd.OptionsFrom(ctx)
+	v, err := cmd.NewVersionManager(options)
+	if err != nil {
+		return err
+	}
+	return v.Use(ctx, u.version, u.isForce, options.Eval)
 }
@@ -0,0 +1,63 @@
+package version
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// Alias 版本别名，Frozen 为 true 时 Target 是设置时解析出的固定版本，
+// 否则 Target 是选择器（如 latest、1.22），每次使用时重新解析
+type Alias struct {
+	Target string `json:"target"`
+	Frozen bool   `json:"frozen"`
+}
+
+var aliasNameRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_.-]*$`)
+
+// versionLikeRegex 匹配看起来像版本号的名字，这类名字不能作为别名，否则会遮蔽真实版本
+var versionLikeRegex = regexp.MustCompile(`^(go)?[0-9]`)
+
+// ValidateAliasName 校验别名名称
+func ValidateAliasName(name string) error {
+	if !aliasNameRegex.MatchString(name) || versionLikeRegex.MatchString(name) || name == "latest" {
+		return fmt.Errorf("invalid alias name: %s", name)
+	}
+	return nil
+}
+
+// ReadAliases 读取别名文件，文件不存在时返回空集合
+func ReadAliases(aliasFilePath string) (map[string]Alias, error) {
+	aliases := make(map[string]Alias)
+	content, err := os.ReadFile(aliasFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return aliases, nil
+		}
+		return nil, fmt.Errorf("failed to read the alias file: %v", err)
+	}
+	if len(content) == 0 {
+		return aliases, nil
+	}
+	if err := json.Unmarshal(content, &aliases); err != nil {
+		return nil, fmt.Errorf("failed to decode the alias file: %v", err)
+	}
+	return aliases, nil
+}
+
+// WriteAliases 写入别名文件
+func WriteAliases(aliasFilePath string, aliases map[string]Alias) error {
+	content, err := json.MarshalIndent(aliases, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode the aliases: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(aliasFilePath), 0755); err != nil {
+		return fmt.Errorf("failed to create the alias file directory: %v", err)
+	}
+	if err := os.WriteFile(aliasFilePath, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write the alias file: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,342 @@
+package version
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aide-cloud/gvm/pkg/dir"
+	"github.com/aide-cloud/gvm/pkg/download"
+	"github.com/aide-cloud/gvm/pkg/lock"
+)
+
+// 离线包是一个未压缩的 tar 文件（归档本身已经压缩），包含：
+//
+//	index.json   go.dev 格式的版本列表，只包含离线包中的归档
+//	SHA256SUMS   每个归档的 SHA-256，格式与 sha256sum 相同
+//	<归档文件>
+const (
+	bundleIndexName = "index.json"
+	bundleSumsName  = "SHA256SUMS"
+)
+
+// BundleCreate 把指定版本在各平台上的归档打包为离线包，缓存中没有的归档先下载到缓存
+func (v *Version) BundleCreate(ctx context.Context, versions string, platforms []Platform, outPath string) error {
+	targets, err := v.resolveSyncVersions(ctx, versions)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("%w: no versions match %s", ErrVersionNotFound, versions)
+	}
+	originVersions, err := v.OriginVersions(ctx, false)
+	if err != nil {
+		return fmt.Errorf("failed to fetch origin versions: %v", err)
+	}
+
+	var index []OriginVersion
+	var archives []cacheArchive
+	sums := make(map[string]string)
+	for _, o := range originVersions {
+		if !slices.Contains(targets, o.Version) {
+			continue
+		}
+		bundled := OriginVersion{Version: o.Version, Stable: o.Stable}
+		for _, platform := range platforms {
+			if _, ok := findArtifactFile(originVersions, o.Version, platform.OS, platform.Arch); !ok {
+				v.logger.Info("not available, skipping", "version", o.Version, "platform", platform)
+				continue
+			}
+			archive, sum, err := v.bundleArchive(ctx, o.Version, platform)
+			if err != nil {
+				return err
+			}
+			archives = append(archives, archive)
+			sums[archive.Filename] = sum
+			bundled.Files = append(bundled.Files, File{
+				Filename: archive.Filename,
+				OS:       platform.OS,
+				Arch:     platform.Arch,
+				Version:  o.Version,
+				SHA256:   sum,
+				Size:     int(archive.Size),
+				Kind:     "archive",
+			})
+		}
+		if len(bundled.Files) > 0 {
+			index = append(index, bundled)
+		}
+	}
+	if len(archives) == 0 {
+		return fmt.Errorf("no archives to bundle")
+	}
+	if err := writeBundle(ctx, outPath, index, archives, sums); err != nil {
+		return err
+	}
+	result := newBundleResult(outPath, index)
+	if !v.printResult(result) {
+		fmt.Printf("Bundled %d archives of %d versions into %s\n", len(result.Archives), len(result.Versions), outPath)
+	}
+	return nil
+}
+
+// bundleArchive 持有版本锁取得缓存中的归档，已缓存的归档按源站摘要重新校验，返回归档及其 SHA-256
+func (v *Version) bundleArchive(ctx context.Context, version string, platform Platform) (cacheArchive, string, error) {
+	artifact, err := v.source.ResolveArtifact(ctx, version, platform.OS, platform.Arch)
+	if err != nil {
+		return cacheArchive{}, "", err
+	}
+	versionLock, err := v.lockVersion(ctx, version)
+	if err != nil {
+		return cacheArchive{}, "", err
+	}
+	defer versionLock.Release()
+
+	archivePath := v.cacheFilePath(artifact.Filename)
+	var sum string
+	if exist, _ := dir.CheckFileExists(archivePath); exist {
+		task := InstallTask{SHA256: artifact.SHA256, H1: artifact.H1, CacheFilePath: archivePath}
+		if task.SHA256 != "" || task.H1 != "" {
+			expected, actual, err := task.checksum(archivePath)
+			if err != nil {
+				return cacheArchive{}, "", fmt.Errorf("failed to hash cache file: %v", err)
+			}
+			if actual != expected {
+				return cacheArchive{}, "", fmt.Errorf("%w for cached %s: expected %s, got %s", ErrChecksumMismatch, archivePath, expected, actual)
+			}
+		}
+		if sum, err = download.SHA256File(archivePath); err != nil {
+			return cacheArchive{}, "", fmt.Errorf("failed to hash cache file: %v", err)
+		}
+	} else if sum, err = v.fetchArtifact(ctx, artifact, archivePath); err != nil {
+		return cacheArchive{}, "", err
+	}
+	info, err := os.Stat(archivePath)
+	if err != nil {
+		return cacheArchive{}, "", fmt.Errorf("failed to stat cache file: %v", err)
+	}
+	return cacheArchive{Filename: artifact.Filename, Path: archivePath, Version: version, OS: platform.OS, Arch: platform.Arch, Size: info.Size()}, sum, nil
+}
+
+// writeBundle 先写入临时文件，完成后再重命名为 outPath
+func writeBundle(ctx context.Context, outPath string, index []OriginVersion, archives []cacheArchive, sums map[string]string) error {
+	indexContent, err := json.MarshalIndent(index, "", " ")
+	if err != nil {
+		return fmt.Errorf("failed to encode the bundle index: %v", err)
+	}
+	var sumsContent bytes.Buffer
+	for _, a := range archives {
+		fmt.Fprintf(&sumsContent, "%s  %s\n", sums[a.Filename], a.Filename)
+	}
+
+	partPath := outPath + ".part"
+	defer os.Remove(partPath)
+	out, err := os.Create(partPath)
+	if err != nil {
+		return fmt.Errorf("failed to create the bundle: %v", err)
+	}
+	defer out.Close()
+	tw := tar.NewWriter(out)
+	now := time.Now()
+	for _, f := range []struct {
+		name    string
+		content []byte
+	}{{bundleIndexName, append(indexContent, '\n')}, {bundleSumsName, sumsContent.Bytes()}} {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: 0644, Size: int64(len(f.content)), ModTime: now}); err != nil {
+			return fmt.Errorf("failed to write the bundle: %v", err)
+		}
+		if _, err := tw.Write(f.content); err != nil {
+			return fmt.Errorf("failed to write the bundle: %v", err)
+		}
+	}
+	for _, a := range archives {
+		if err := writeBundleArchive(ctx, tw, a); err != nil {
+			return fmt.Errorf("failed to write %s to the bundle: %v", a.Filename, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write the bundle: %v", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write the bundle: %v", err)
+	}
+	if err := os.Rename(partPath, outPath); err != nil {
+		return fmt.Errorf("failed to save the bundle: %v", err)
+	}
+	return nil
+}
+
+func writeBundleArchive(ctx context.Context, tw *tar.Writer, a cacheArchive) error {
+	f, err := os.Open(a.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{Name: a.Filename, Mode: 0644, Size: info.Size(), ModTime: info.ModTime()}); err != nil {
+		return err
+	}
+	_, err = download.Copy(ctx, tw, f)
+	return err
+}
+
+// BundleImport 把离线包中的归档校验后放入缓存目录，并把其版本列表合并到版本缓存，
+// 之后不需要网络即可安装离线包中的版本
+func (v *Version) BundleImport(ctx context.Context, bundlePath string) error {
+	f, err := os.Open(bundlePath)
+	if err != nil {
+		return fmt.Errorf("failed to open the bundle: %v", err)
+	}
+	defer f.Close()
+
+	var index []OriginVersion
+	sums := make(map[string]string)
+	imported := []string{}
+	tr := tar.NewReader(f)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read the bundle: %v", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		switch header.Name {
+		case bundleIndexName:
+			if err := json.NewDecoder(tr).Decode(&index); err != nil {
+				return fmt.Errorf("failed to decode the bundle index: %v", err)
+			}
+		case bundleSumsName:
+			scanner := bufio.NewScanner(tr)
+			for scanner.Scan() {
+				if sum, name, ok := strings.Cut(scanner.Text(), "  "); ok {
+					sums[name] = sum
+				}
+			}
+			if err := scanner.Err(); err != nil {
+				return fmt.Errorf("failed to read the bundle checksums: %v", err)
+			}
+		default:
+			// 摘要文件在归档之前写入，归档到达时已经可以校验
+			if err := v.importBundleArchive(ctx, header.Name, tr, sums[header.Name]); err != nil {
+				return err
+			}
+			imported = append(imported, header.Name)
+		}
+	}
+	if len(index) == 0 {
+		return fmt.Errorf("no index found in the bundle")
+	}
+	if err := validateOriginVersions(index); err != nil {
+		return fmt.Errorf("invalid bundle index: %v", err)
+	}
+	if err := v.mergeOriginCache(ctx, index); err != nil {
+		return err
+	}
+	result := newBundleResult(bundlePath, index)
+	result.Archives = imported
+	if !v.printResult(result) {
+		fmt.Printf("Imported %d archives of %d versions into %s\n", len(imported), len(index), v.cacheDir)
+	}
+	return nil
+}
+
+// newBundleResult 按离线包的版本列表生成结果
+func newBundleResult(bundlePath string, index []OriginVersion) BundleResult {
+	result := BundleResult{Bundle: bundlePath, Versions: []string{}, Archives: []string{}}
+	for _, o := range index {
+		result.Versions = append(result.Versions, o.Version)
+		for _, f := range o.Files {
+			result.Archives = append(result.Archives, f.Filename)
+		}
+	}
+	return result
+}
+
+// importBundleArchive 持有版本锁把归档写入缓存目录，校验通过后才替换缓存中的文件
+func (v *Version) importBundleArchive(ctx context.Context, name string, r io.Reader, expected string) error {
+	version, _, _, ok := parseArchiveFilename(name)
+	if !ok || strings.ContainsAny(name, "/\\") {
+		return fmt.Errorf("unexpected file %q in the bundle", name)
+	}
+	if !sha256Regex.MatchString(expected) {
+		return fmt.Errorf("no checksum for %s in the bundle", name)
+	}
+	versionLock, err := v.lockVersion(ctx, version)
+	if err != nil {
+		return err
+	}
+	defer versionLock.Release()
+
+	archivePath := v.cacheFilePath(name)
+	partPath := archivePath + ".part"
+	defer os.Remove(partPath)
+	out, err := os.Create(partPath)
+	if err != nil {
+		return fmt.Errorf("failed to create cache file: %v", err)
+	}
+	hash := sha256.New()
+	_, err = download.Copy(ctx, io.MultiWriter(out, hash), r)
+	out.Close()
+	if err != nil {
+		return fmt.Errorf("failed to write cache file: %v", err)
+	}
+	if sum := hex.EncodeToString(hash.Sum(nil)); sum != expected {
+		return fmt.Errorf("%w for %s in the bundle: expected %s, got %s", ErrChecksumMismatch, name, expected, sum)
+	}
+	if err := os.Rename(partPath, archivePath); err != nil {
+		return fmt.Errorf("failed to save cache file: %v", err)
+	}
+	if err := os.WriteFile(checksumFilePath(archivePath), []byte(expected+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write checksum file: %v", err)
+	}
+	v.logger.Info("imported archive", "cacheFilePath", archivePath)
+	return nil
+}
+
+// mergeOriginCache 持有版本缓存锁把离线包的版本列表合并到 go.dev 来源的版本缓存，
+// 没有缓存时以离线包的版本列表新建缓存，过期后联网失败会继续使用该缓存
+func (v *Version) mergeOriginCache(ctx context.Context, index []OriginVersion) error {
+	cacheLock, err := lock.Acquire(ctx, v.versionFilePath+".lock", v.lockTimeout, v.logger)
+	if err != nil {
+		return err
+	}
+	defer cacheLock.Release()
+
+	cache := readOriginCache(v.versionFilePath, v.originURL)
+	if cache == nil {
+		cache = &originCache{SourceURL: v.originURL, FetchedAt: time.Now()}
+	}
+	for _, bundled := range index {
+		i := slices.IndexFunc(cache.Versions, func(o OriginVersion) bool { return o.Version == bundled.Version })
+		if i < 0 {
+			cache.Versions = append(cache.Versions, bundled)
+			continue
+		}
+		for _, file := range bundled.Files {
+			if _, ok := findOriginFile(cache.Versions[i:i+1], file.Filename); !ok {
+				cache.Versions[i].Files = append(cache.Versions[i].Files, file)
+			}
+		}
+	}
+	slices.SortStableFunc(cache.Versions, func(a, b OriginVersion) int {
+		return CompareVersions(b.Version, a.Version)
+	})
+	return writeOriginCache(v.versionFilePath, cache)
+}
@@ -0,0 +1,324 @@
+package version
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aide-cloud/gvm/pkg/dir"
+	"github.com/aide-cloud/gvm/pkg/download"
+	"github.com/aide-cloud/gvm/pkg/lock"
+)
+
+var archiveSuffixes = []string{".tar.gz", ".zip"}
+
+// parseArchiveFilename 解析 go1.21.3.linux-amd64.tar.gz 形式的归档文件名
+func parseArchiveFilename(filename string) (version, goos, goarch string, ok bool) {
+	name := filename
+	for _, suffix := range archiveSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			name = strings.TrimSuffix(name, suffix)
+			ok = true
+			break
+		}
+	}
+	if !ok {
+		return "", "", "", false
+	}
+	i := strings.LastIndex(name, ".")
+	if i < 0 {
+		return "", "", "", false
+	}
+	version = name[:i]
+	goos, goarch, found := strings.Cut(name[i+1:], "-")
+	if !found || !strings.HasPrefix(version, "go") {
+		return "", "", "", false
+	}
+	return version, goos, goarch, true
+}
+
+type cacheArchive struct {
+	Filename string
+	Path     string
+	Version  string
+	OS       string
+	Arch     string
+	Size     int64
+	ModTime  time.Time
+}
+
+// listCacheArchives 列出缓存目录中的 go 归档文件
+func (v *Version) listCacheArchives() ([]cacheArchive, error) {
+	dis, err := os.ReadDir(v.cacheDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read the cache directory: %v", err)
+	}
+	var archives []cacheArchive
+	for _, di := range dis {
+		if di.IsDir() {
+			continue
+		}
+		version, goos, goarch, ok := parseArchiveFilename(di.Name())
+		if !ok {
+			continue
+		}
+		info, err := di.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat the cache file: %v", err)
+		}
+		archives = append(archives, cacheArchive{
+			Filename: di.Name(),
+			Path:     v.cacheFilePath(di.Name()),
+			Version:  version,
+			OS:       goos,
+			Arch:     goarch,
+			Size:     info.Size(),
+			ModTime:  info.ModTime(),
+		})
+	}
+	return archives, nil
+}
+
+// checksumFilePath 校验通过后写入的摘要文件，用于标记归档已校验
+func checksumFilePath(archivePath string) string {
+	return archivePath + ".sha256"
+}
+
+// isVerified 摘要文件存在且不早于归档文件时认为归档已校验
+func (a cacheArchive) isVerified() bool {
+	info, err := os.Stat(checksumFilePath(a.Path))
+	if err != nil {
+		return false
+	}
+	return !info.ModTime().Before(a.ModTime)
+}
+
+// removeCacheArchive 持有版本锁删除缓存归档，避免删除正在安装中的归档
+func (v *Version) removeCacheArchive(ctx context.Context, a cacheArchive) error {
+	versionLock, err := v.lockVersion(ctx, a.Version)
+	if err != nil {
+		return err
+	}
+	defer versionLock.Release()
+	if err := os.Remove(a.Path); err != nil {
+		return err
+	}
+	if err := os.Remove(checksumFilePath(a.Path)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// removeStaleLocks 删除既未安装也没有缓存归档的版本的锁文件，正被其他进程持有的锁保留
+func (v *Version) removeStaleLocks(ctx context.Context) {
+	dis, err := os.ReadDir(v.lockDir())
+	if err != nil {
+		return
+	}
+	for _, di := range dis {
+		version, ok := strings.CutSuffix(di.Name(), ".lock")
+		if !ok || di.IsDir() {
+			continue
+		}
+		versionLock, err := lock.Acquire(ctx, v.versionLockPath(version), 0, nil)
+		if err != nil {
+			continue
+		}
+		// 持有锁后再检查，避免删除其他进程刚安装或下载的版本的锁
+		if v.versionInUse(version) {
+			_ = versionLock.Release()
+			continue
+		}
+		if err := versionLock.Remove(); err != nil {
+			v.logger.Debug("failed to remove lock file", "version", version, "error", err)
+		}
+	}
+}
+
+// versionInUse 版本已安装或缓存中有其归档
+func (v *Version) versionInUse(version string) bool {
+	if _, err := os.Stat(v.SdkFilePath(version)); err == nil {
+		return true
+	}
+	archives, err := v.listCacheArchives()
+	return err != nil || slices.ContainsFunc(archives, func(a cacheArchive) bool { return a.Version == version })
+}
+
+// ParseAge 解析时长，在 time.ParseDuration 的基础上支持 d（天）和 w（周）
+func ParseAge(s string) (time.Duration, error) {
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	for suffix, unit := range units {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			days, err := strconv.ParseFloat(n, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid age: %s", s)
+			}
+			return time.Duration(days * float64(unit)), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid age: %s", s)
+	}
+	return d, nil
+}
+
+// formatAge 将时长格式化为 3d、5h、12m 的形式
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+}
+
+func (v *Version) CachePath() {
+	if v.printResult(CachePathResult{Path: v.cacheDir}) {
+		return
+	}
+	fmt.Println(v.cacheDir)
+}
+
+func (a cacheArchive) item() CacheItem {
+	return CacheItem{
+		Filename: a.Filename,
+		Path:     a.Path,
+		Version:  a.Version,
+		OS:       a.OS,
+		Arch:     a.Arch,
+		Size:     a.Size,
+		ModTime:  a.ModTime,
+		Verified: a.isVerified(),
+	}
+}
+
+func (v *Version) CacheLs() error {
+	archives, err := v.listCacheArchives()
+	if err != nil {
+		return err
+	}
+	if v.output.IsStructured() {
+		items := make([]CacheItem, 0, len(archives))
+		for _, a := range archives {
+			items = append(items, a.item())
+		}
+		v.printResult(items)
+		return nil
+	}
+	if len(archives) == 0 {
+		v.logger.Info("No cache archives found")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ARCHIVE\tVERSION\tSIZE\tAGE\tVERIFIED")
+	for _, a := range archives {
+		verified := "no"
+		if a.isVerified() {
+			verified = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", a.Filename, a.Version, dir.FormatSize(a.Size), formatAge(time.Since(a.ModTime)), verified)
+	}
+	return w.Flush()
+}
+
+func (v *Version) CacheClean(ctx context.Context, olderThan time.Duration, isKeepInstalled bool) error {
+	archives, err := v.listCacheArchives()
+	if err != nil {
+		return err
+	}
+	installed, err := FetchLocalVersions(v.sdkDir)
+	if err != nil {
+		return err
+	}
+	result := CacheCleanResult{Removed: []CacheItem{}}
+	for _, a := range archives {
+		if olderThan > 0 && time.Since(a.ModTime) < olderThan {
+			continue
+		}
+		if isKeepInstalled && slices.Contains(installed, a.Version) {
+			continue
+		}
+		item := a.item()
+		if err := v.removeCacheArchive(ctx, a); err != nil {
+			v.printResult(result)
+			return fmt.Errorf("failed to remove archive: %w", err)
+		}
+		result.Removed = append(result.Removed, item)
+		result.Reclaimed += a.Size
+		v.logger.Info("removed archive", "path", a.Path, "size", dir.FormatSize(a.Size))
+	}
+	v.removeStaleLocks(ctx)
+	if v.printResult(result) {
+		return nil
+	}
+	fmt.Printf("Reclaimed: %s\n", dir.FormatSize(result.Reclaimed))
+	return nil
+}
+
+// CacheVerify 按源站元数据重新校验缓存归档的 SHA-256，存在不一致的归档时返回 ErrChecksumMismatch
+func (v *Version) CacheVerify(ctx context.Context) error {
+	archives, err := v.listCacheArchives()
+	if err != nil {
+		return err
+	}
+	if len(archives) == 0 {
+		v.logger.Info("No cache archives found")
+		v.printResult([]CacheVerifyItem{})
+		return nil
+	}
+	originVersions, err := v.OriginVersions(ctx, false)
+	if err != nil {
+		return fmt.Errorf("failed to fetch origin versions: %w", err)
+	}
+	out := v.textOut()
+	items := make([]CacheVerifyItem, 0, len(archives))
+	mismatched := 0
+	for _, a := range archives {
+		item := CacheVerifyItem{Filename: a.Filename, Status: VerifyUnknown}
+		file, ok := findOriginFile(originVersions, a.Filename)
+		if !ok || file.SHA256 == "" {
+			items = append(items, item)
+			fmt.Fprintf(out, "%-40s unknown (no checksum in origin metadata)\n", a.Filename)
+			continue
+		}
+		item.Expected = file.SHA256
+		sum, err := download.SHA256File(a.Path)
+		if err != nil {
+			item.Error = err.Error()
+			items = append(items, item)
+			v.logger.Error("Failed to hash archive:", "path", a.Path, "error", err)
+			continue
+		}
+		item.Actual = sum
+		if sum != file.SHA256 {
+			_ = os.Remove(checksumFilePath(a.Path))
+			mismatched++
+			item.Status = VerifyMismatch
+			items = append(items, item)
+			fmt.Fprintf(out, "%-40s MISMATCH (expected %s, got %s)\n", a.Filename, file.SHA256, sum)
+			continue
+		}
+		if err := os.WriteFile(checksumFilePath(a.Path), []byte(sum+"\n"), 0644); err != nil {
+			v.logger.Error("Failed to write checksum file:", "error", err)
+		}
+		item.Status = VerifyOK
+		items = append(items, item)
+		fmt.Fprintf(out, "%-40s ok\n", a.Filename)
+	}
+	v.printResult(items)
+	if mismatched > 0 {
+		return fmt.Errorf("%w: %d cached archives", ErrChecksumMismatch, mismatched)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package version
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCacheCleanRemovesStaleLocks(t *testing.T) {
+	fake := &fakeSource{archives: map[string][]byte{"go1.22.1": buildSdkArchive(t, "go1.22.1")}}
+	v := newTestVersion(t, WithSource(fake))
+	ctx := context.Background()
+	if _, err := v.InstallVersion(ctx, "go1.22.1", false, func(string, ...any) {}); err != nil {
+		t.Fatal(err)
+	}
+	lockPath := filepath.Join(v.cacheDir, "locks", "go1.22.1.lock")
+	if _, err := os.Stat(lockPath); err != nil {
+		t.Fatalf("version lock not in the locks directory: %v", err)
+	}
+	if matches, _ := filepath.Glob(filepath.Join(v.cacheDir, "*.lock")); len(matches) != 0 {
+		t.Errorf("lock files left in the cache directory: %v", matches)
+	}
+
+	// 版本仍然安装时保留锁文件
+	if err := v.CacheClean(ctx, 0, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(lockPath); err != nil {
+		t.Fatalf("lock of an installed version was removed: %v", err)
+	}
+
+	if err := v.UninstallVersion(ctx, "go1.22.1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.CacheClean(ctx, 0, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Errorf("stale lock file was not removed: %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package version
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// goVersionRegex 匹配 go1.21.3、1.21、go1.22rc1、1.21beta2 等版本格式
+var goVersionRegex = regexp.MustCompile(`^(?:go)?(\d+)\.(\d+)(?:\.(\d+))?(?:(beta|rc)(\d+))?$`)
+
+type goVersion struct {
+	major, minor, patch int
+	// pre 预发布阶段：0 为 beta，1 为 rc，2 为正式版
+	pre    int
+	preNum int
+}
+
+func parseGoVersion(s string) (goVersion, error) {
+	m := goVersionRegex.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return goVersion{}, fmt.Errorf("invalid go version: %s", s)
+	}
+	gv := goVersion{pre: 2}
+	gv.major, _ = strconv.Atoi(m[1])
+	gv.minor, _ = strconv.Atoi(m[2])
+	if m[3] != "" {
+		gv.patch, _ = strconv.Atoi(m[3])
+	}
+	switch m[4] {
+	case "beta":
+		gv.pre = 0
+	case "rc":
+		gv.pre = 1
+	}
+	if m[5] != "" {
+		gv.preNum, _ = strconv.Atoi(m[5])
+	}
+	return gv, nil
+}
+
+func (a goVersion) compare(b goVersion) int {
+	return cmp.Or(
+		cmp.Compare(a.major, b.major),
+		cmp.Compare(a.minor, b.minor),
+		cmp.Compare(a.patch, b.patch),
+		cmp.Compare(a.pre, b.pre),
+		cmp.Compare(a.preNum, b.preNum),
+	)
+}
+
+// minorLine 返回版本所属的次版本线，如 go1.21.3 -> go1.21
+func (a goVersion) minorLine() string {
+	return fmt.Sprintf("go%d.%d", a.major, a.minor)
+}
+
+// CompareVersions 比较两个 go 版本，无法解析的版本按字符串比较并排在可解析版本之前
+func CompareVersions(a, b string) int {
+	va, errA := parseGoVersion(a)
+	vb, errB := parseGoVersion(b)
+	switch {
+	case errA != nil && errB != nil:
+		return strings.Compare(a, b)
+	case errA != nil:
+		return -1
+	case errB != nil:
+		return 1
+	}
+	return va.compare(vb)
+}
+
+type versionCondition struct {
+	op      string
+	version goVersion
+}
+
+// Constraint 版本约束表达式，多个条件用逗号分隔且需同时满足，如 ">=1.21,<1.23"
+type Constraint struct {
+	conditions []versionCondition
+}
+
+var constraintOps = []string{">=", "<=", "!=", ">", "<", "="}
+
+// IsConstraint 判断参数是否是约束表达式而不是具体版本
+func IsConstraint(s string) bool {
+	return s != "" && strings.ContainsRune("<>=!", rune(s[0]))
+}
+
+// ParseConstraint 解析约束表达式
+func ParseConstraint(expr string) (Constraint, error) {
+	var c Constraint
+	for _, part := range strings.Split(expr, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		op := "="
+		for _, o := range constraintOps {
+			if strings.HasPrefix(part, o) {
+				op = o
+				break
+			}
+		}
+		gv, err := parseGoVersion(strings.TrimSpace(strings.TrimPrefix(part, op)))
+		if err != nil {
+			return Constraint{}, fmt.Errorf("invalid constraint %q: %v", expr, err)
+		}
+		c.conditions = append(c.conditions, versionCondition{op: op, version: gv})
+	}
+	if len(c.conditions) == 0 {
+		return Constraint{}, fmt.Errorf("empty constraint")
+	}
+	return c, nil
+}
+
+// Match 判断版本是否满足约束，无法解析的版本永远不匹配
+func (c Constraint) Match(version string) bool {
+	gv, err := parseGoVersion(version)
+	if err != nil {
+		return false
+	}
+	for _, cond := range c.conditions {
+		r := gv.compare(cond.version)
+		var ok bool
+		switch cond.op {
+		case ">=":
+			ok = r >= 0
+		case "<=":
+			ok = r <= 0
+		case "!=":
+			ok = r != 0
+		case ">":
+			ok = r > 0
+		case "<":
+			ok = r < 0
+		default:
+			ok = r == 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,17 @@
+package version
+
+import "errors"
+
+// 可以用 errors.Is 判断的错误类型，命令行按类型映射为不同的退出码
+var (
+	// ErrVersionNotFound 版本来源中没有指定的版本或当前平台的归档
+	ErrVersionNotFound = errors.New("version not found")
+	// ErrNotInstalled 指定的版本没有安装
+	ErrNotInstalled = errors.New("version not installed")
+	// ErrChecksumMismatch 归档的摘要与版本来源提供的不一致
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	// ErrUnsupportedShell 无法为当前 shell 设置 GOROOT
+	ErrUnsupportedShell = errors.New("unsupported shell")
+	// ErrActiveVersion 不能卸载当前使用中的版本
+	ErrActiveVersion = errors.New("cannot uninstall the active version")
+)
@@ -0,0 +1,270 @@
+package version
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"golang.org/x/mod/sumdb"
+	"golang.org/x/mod/sumdb/note"
+
+	"github.com/aide-cloud/gvm/pkg/download"
+	"github.com/aide-cloud/gvm/pkg/log"
+)
+
+const (
+	toolchainModule = "golang.org/toolchain"
+	// toolchainModulePrefix 工具链模块版本的固定前缀，如 v0.0.1-go1.22.3.linux-amd64
+	toolchainModulePrefix = "v0.0.1-"
+	defaultGoProxy        = "https://proxy.golang.org,direct"
+	defaultGoSumDB        = "sum.golang.org"
+)
+
+// knownSumDBKeys go 命令内置的校验和数据库公钥
+var knownSumDBKeys = map[string]string{
+	"sum.golang.org": "sum.golang.org+033de0ae+Ac4zctda0e5eza+HJyk9SxEdh+s3Ux18htTTAD8OuAn8",
+}
+
+// GoProxyConfig 模块代理配置，含义与 go 命令的同名环境变量一致
+type GoProxyConfig struct {
+	GoProxy   string
+	GoSumDB   string
+	GoNoSumDB string
+	GoPrivate string
+}
+
+// goProxyEntry GOPROXY 中的一个代理。fallbackOnError 为 true 表示其后以 | 分隔，任何错误都尝试下一个代理；
+// 以 , 分隔时只有 404 和 410 才尝试下一个
+type goProxyEntry struct {
+	url             string
+	fallbackOnError bool
+}
+
+// proxies 解析 GOPROXY，跳过不适用于工具链下载的 direct
+func (c GoProxyConfig) proxies() ([]goProxyEntry, error) {
+	goProxy := c.GoProxy
+	if goProxy == "" {
+		goProxy = defaultGoProxy
+	}
+	var proxies []goProxyEntry
+	for rest := goProxy; rest != ""; {
+		p, fallbackOnError := rest, false
+		if i := strings.IndexAny(rest, ",|"); i >= 0 {
+			p, fallbackOnError, rest = rest[:i], rest[i] == '|', rest[i+1:]
+		} else {
+			rest = ""
+		}
+		switch p = strings.TrimSpace(p); p {
+		case "", "direct":
+			continue
+		case "off":
+			if len(proxies) == 0 {
+				return nil, fmt.Errorf("module proxy disabled by GOPROXY=off")
+			}
+			return proxies, nil
+		}
+		proxies = append(proxies, goProxyEntry{url: strings.TrimSuffix(p, "/"), fallbackOnError: fallbackOnError})
+	}
+	if len(proxies) == 0 {
+		return nil, fmt.Errorf("no module proxy found in GOPROXY=%s", goProxy)
+	}
+	return proxies, nil
+}
+
+// tryProxies 按 GOPROXY 的回退规则依次调用 fn，直到某个代理成功或者不允许继续回退
+func tryProxies(ctx context.Context, proxies []goProxyEntry, fn func(proxy string) error) error {
+	var errs []error
+	for _, p := range proxies {
+		err := fn(p.url)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", p.url, err))
+		var statusErr *download.StatusError
+		notFound := errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusNotFound || statusErr.StatusCode == http.StatusGone)
+		if !notFound && !p.fallbackOnError {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sumDB 校验和数据库，key 为 note 格式的公钥
+type sumDB struct {
+	name string
+	key  string
+	url  string
+}
+
+// sumDB 解析 GOSUMDB，被 GOSUMDB=off、GONOSUMDB 或 GOPRIVATE 关闭时 ok 为 false。
+// GOSUMDB 只给出名称时必须是 go 命令内置公钥的数据库，否则需要写成 name+hash+key [url]
+func (c GoProxyConfig) sumDB() (db sumDB, ok bool, err error) {
+	if matchModulePatterns(c.GoNoSumDB, toolchainModule) || matchModulePatterns(c.GoPrivate, toolchainModule) {
+		return sumDB{}, false, nil
+	}
+	goSumDB := strings.TrimSpace(c.GoSumDB)
+	if goSumDB == "" {
+		goSumDB = defaultGoSumDB
+	}
+	if goSumDB == "off" {
+		return sumDB{}, false, nil
+	}
+	// 与 go 命令相同，sum.golang.google.cn 是 sum.golang.org 在中国大陆的访问地址
+	if goSumDB == "sum.golang.google.cn" {
+		goSumDB = "sum.golang.org https://sum.golang.google.cn"
+	}
+	fields := strings.Fields(goSumDB)
+	key := fields[0]
+	if !strings.Contains(key, "+") {
+		known, found := knownSumDBKeys[key]
+		if !found {
+			return sumDB{}, false, fmt.Errorf("unknown checksum database %q in GOSUMDB, expected name+hash+key", key)
+		}
+		key = known
+	}
+	verifier, err := note.NewVerifier(key)
+	if err != nil {
+		return sumDB{}, false, fmt.Errorf("invalid checksum database key in GOSUMDB: %v", err)
+	}
+	db = sumDB{name: verifier.Name(), key: key, url: "https://" + verifier.Name()}
+	if len(fields) > 1 {
+		db.url = strings.TrimSuffix(fields[1], "/")
+	}
+	return db, true, nil
+}
+
+// matchModulePatterns 判断模块路径或其任意前缀是否匹配逗号分隔的 glob 模式列表
+func matchModulePatterns(patterns, modulePath string) bool {
+	for _, pattern := range strings.Split(patterns, ",") {
+		if pattern = strings.TrimSpace(pattern); pattern == "" {
+			continue
+		}
+		elems := strings.Split(modulePath, "/")
+		if n := strings.Count(pattern, "/") + 1; n < len(elems) {
+			elems = elems[:n]
+		}
+		prefix := strings.Join(elems, "/")
+		if ok, _ := path.Match(pattern, prefix); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// toolchainModVersion 返回工具链模块版本，如 v0.0.1-go1.22.3.linux-amd64
+func toolchainModVersion(version, goos, goarch string) string {
+	return fmt.Sprintf("%s%s.%s-%s", toolchainModulePrefix, version, goos, goarch)
+}
+
+// toolchainZipURL 工具链模块 zip 在代理上的下载地址
+func toolchainZipURL(proxy, version, goos, goarch string) (string, error) {
+	return url.JoinPath(proxy, toolchainModule, "@v", toolchainModVersion(version, goos, goarch)+".zip")
+}
+
+// parseToolchainList 将 /@v/list 的结果转换为与 go.dev 相同结构的版本列表，按版本从新到旧排列
+func parseToolchainList(content []byte) ([]OriginVersion, error) {
+	var filenames []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if modVersion, ok := strings.CutPrefix(strings.TrimSpace(line), toolchainModulePrefix); ok {
+			filenames = append(filenames, modVersion+".zip")
+		}
+	}
+	return originVersionsFromFilenames(filenames), nil
+}
+
+// goProxySource 从 GOPROXY 获取 golang.org/toolchain 模块形式的工具链，按校验和数据库中经过签名验证的 h1 哈希校验
+type goProxySource struct {
+	client *http.Client
+	config GoProxyConfig
+	cache  originCacheFile
+	// sumDBDir 保存已验证的校验和数据库签名树和 tile
+	sumDBDir string
+	logger   log.Logger
+}
+
+// ListVersions 按 GOPROXY 的回退规则从代理获取工具链模块的版本列表
+func (s *goProxySource) ListVersions(ctx context.Context, forceUpdate bool) ([]OriginVersion, error) {
+	proxies, err := s.config.proxies()
+	if err != nil {
+		return nil, err
+	}
+	var originVersions []OriginVersion
+	err = tryProxies(ctx, proxies, func(proxy string) error {
+		listURL, err := url.JoinPath(proxy, toolchainModule, "@v", "list")
+		if err != nil {
+			return fmt.Errorf("invalid module proxy: %v", err)
+		}
+		originVersions, err = s.cache.fetch(ctx, s.client, listURL, forceUpdate, parseToolchainList)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list toolchains from GOPROXY: %w", err)
+	}
+	return originVersions, nil
+}
+
+// ResolveArtifact 按 GOPROXY 的回退规则选择提供该工具链的代理，并从校验和数据库查询经过验证的 h1 哈希；
+// 无法得到经过验证的哈希时返回错误，只有 GOSUMDB=off、GONOSUMDB 或 GOPRIVATE 明确关闭时才跳过校验
+func (s *goProxySource) ResolveArtifact(ctx context.Context, version, goos, goarch string) (Artifact, error) {
+	proxies, err := s.config.proxies()
+	if err != nil {
+		return Artifact{}, err
+	}
+	db, verify, err := s.config.sumDB()
+	if err != nil {
+		return Artifact{}, err
+	}
+	artifact := Artifact{Filename: archiveFilename(version, goos, goarch, ".zip")}
+	err = tryProxies(ctx, proxies, func(proxy string) error {
+		zipURL, err := toolchainZipURL(proxy, version, goos, goarch)
+		if err != nil {
+			return fmt.Errorf("failed to join download file url: %v", err)
+		}
+		if err := headURL(ctx, s.client, zipURL); err != nil {
+			return err
+		}
+		artifact.URL = zipURL
+		return nil
+	})
+	if err != nil {
+		return Artifact{}, fmt.Errorf("failed to find %s in GOPROXY: %w", version, err)
+	}
+	if !verify {
+		s.logger.Warn("Checksum database disabled by GOSUMDB/GONOSUMDB/GOPRIVATE", "version", version)
+		return artifact, nil
+	}
+	if artifact.H1, err = s.lookupHash(ctx, db, proxies, toolchainModVersion(version, goos, goarch)); err != nil {
+		return Artifact{}, fmt.Errorf("failed to verify %s against checksum database %s: %w", version, db.name, err)
+	}
+	return artifact, nil
+}
+
+func (s *goProxySource) OpenArtifact(ctx context.Context, artifact Artifact) (io.ReadCloser, error) {
+	return download.Open(ctx, s.client, artifact.URL)
+}
+
+// lookupHash 从校验和数据库查询工具链模块 zip 的 h1 哈希。sumdb.Client 校验数据库的签名、
+// 记录所在的树以及与本地保存的上一棵树的一致性，因此通过代理访问数据库也是安全的
+func (s *goProxySource) lookupHash(ctx context.Context, db sumDB, proxies []goProxyEntry, modVersion string) (string, error) {
+	ops := &sumDBOps{ctx: ctx, client: s.client, db: db, proxies: proxies, dir: s.sumDBDir, logger: s.logger}
+	lines, err := sumdb.NewClient(ops).Lookup(toolchainModule, modVersion)
+	if err != nil {
+		return "", err
+	}
+	// 记录格式：golang.org/toolchain v0.0.1-go1.22.3.linux-amd64 h1:xxx=
+	prefix := toolchainModule + " " + modVersion + " "
+	for _, line := range lines {
+		if hash, ok := strings.CutPrefix(line, prefix); ok && strings.HasPrefix(hash, "h1:") {
+			return hash, nil
+		}
+	}
+	return "", fmt.Errorf("no hash found for %s@%s", toolchainModule, modVersion)
+}
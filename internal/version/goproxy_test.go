@@ -0,0 +1,191 @@
+package version
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/mod/sumdb"
+	"golang.org/x/mod/sumdb/note"
+
+	"github.com/aide-cloud/gvm/pkg/log"
+)
+
+const testToolchainHash = "h1:Kyq6y5+0ZBSZQzcxUyIxw93Yi6hcLGhp3hlrxKgb5Wc="
+
+// newTestProxy 返回提供工具链列表和 zip 的代理，sumdb 非空时同时在 /sumdb/<name>/ 下转发校验和数据库
+func newTestProxy(t *testing.T, sumDBName string, sumDB http.Handler) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/golang.org/toolchain/@v/list", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "v0.0.1-go1.22.1.linux-amd64")
+	})
+	mux.HandleFunc("/golang.org/toolchain/@v/", func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, ".zip") {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	if sumDB != nil {
+		prefix := "/sumdb/" + sumDBName
+		mux.Handle(prefix+"/", http.StripPrefix(prefix, sumDB))
+	}
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newStatusServer(t *testing.T, status int) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newTestSumDB 返回签名的校验和数据库及其公钥
+func newTestSumDB(t *testing.T) (http.Handler, string) {
+	t.Helper()
+	skey, vkey, err := note.GenerateKey(rand.Reader, "sumdb.test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gosum := func(path, vers string) ([]byte, error) {
+		return []byte(fmt.Sprintf("%s %s %s\n%s %s/go.mod h1:mod=\n", path, vers, testToolchainHash, path, vers)), nil
+	}
+	return sumdb.NewServer(sumdb.NewTestServer(skey, gosum)), vkey
+}
+
+func newTestGoProxySource(t *testing.T, config GoProxyConfig) *goProxySource {
+	t.Helper()
+	dir := t.TempDir()
+	return &goProxySource{
+		client:   http.DefaultClient,
+		config:   config,
+		cache:    originCacheFile{path: filepath.Join(dir, "versions.json"), lockTimeout: time.Second, logger: log.Default()},
+		sumDBDir: filepath.Join(dir, "sumdb"),
+		logger:   log.Default(),
+	}
+}
+
+func TestGoProxyFallback(t *testing.T) {
+	ok := newTestProxy(t, "", nil)
+	notFound := newStatusServer(t, http.StatusNotFound)
+	broken := newStatusServer(t, http.StatusInternalServerError)
+	tests := []struct {
+		goProxy string
+		wantErr bool
+	}{
+		{goProxy: notFound.URL + "," + ok.URL},
+		{goProxy: broken.URL + "," + ok.URL, wantErr: true},
+		{goProxy: broken.URL + "|" + ok.URL},
+		{goProxy: "direct," + ok.URL},
+		{goProxy: broken.URL + ",off", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.goProxy, func(t *testing.T) {
+			s := newTestGoProxySource(t, GoProxyConfig{GoProxy: tt.goProxy, GoSumDB: "off"})
+			_, listErr := s.ListVersions(context.Background(), true)
+			artifact, resolveErr := s.ResolveArtifact(context.Background(), "go1.22.1", "linux", "amd64")
+			if tt.wantErr {
+				if listErr == nil || resolveErr == nil {
+					t.Fatalf("expected errors, got %v and %v", listErr, resolveErr)
+				}
+				return
+			}
+			if listErr != nil || resolveErr != nil {
+				t.Fatalf("unexpected errors %v and %v", listErr, resolveErr)
+			}
+			if !strings.HasPrefix(artifact.URL, ok.URL) {
+				t.Errorf("artifact url %s, want it from %s", artifact.URL, ok.URL)
+			}
+		})
+	}
+}
+
+func TestGoProxyVerifiesSumDB(t *testing.T) {
+	db, vkey := newTestSumDB(t)
+	dbServer := httptest.NewServer(db)
+	t.Cleanup(dbServer.Close)
+
+	t.Run("direct", func(t *testing.T) {
+		proxy := newTestProxy(t, "", nil)
+		s := newTestGoProxySource(t, GoProxyConfig{GoProxy: proxy.URL, GoSumDB: vkey + " " + dbServer.URL})
+		artifact, err := s.ResolveArtifact(context.Background(), "go1.22.1", "linux", "amd64")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if artifact.H1 != testToolchainHash {
+			t.Errorf("H1 = %q, want %q", artifact.H1, testToolchainHash)
+		}
+	})
+
+	t.Run("through the proxy", func(t *testing.T) {
+		proxy := newTestProxy(t, "sumdb.test", db)
+		s := newTestGoProxySource(t, GoProxyConfig{GoProxy: proxy.URL, GoSumDB: vkey + " http://127.0.0.1:1"})
+		artifact, err := s.ResolveArtifact(context.Background(), "go1.22.1", "linux", "amd64")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if artifact.H1 != testToolchainHash {
+			t.Errorf("H1 = %q, want %q", artifact.H1, testToolchainHash)
+		}
+	})
+
+	t.Run("unsigned response", func(t *testing.T) {
+		forged := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprintf(w, "golang.org/toolchain v0.0.1-go1.22.1.linux-amd64 h1:forged=\n")
+		}))
+		t.Cleanup(forged.Close)
+		proxy := newTestProxy(t, "", nil)
+		s := newTestGoProxySource(t, GoProxyConfig{GoProxy: proxy.URL, GoSumDB: vkey + " " + forged.URL})
+		if artifact, err := s.ResolveArtifact(context.Background(), "go1.22.1", "linux", "amd64"); err == nil {
+			t.Fatalf("accepted an unsigned hash %q", artifact.H1)
+		}
+	})
+
+	t.Run("unavailable", func(t *testing.T) {
+		proxy := newTestProxy(t, "", nil)
+		s := newTestGoProxySource(t, GoProxyConfig{GoProxy: proxy.URL, GoSumDB: vkey + " http://127.0.0.1:1"})
+		if _, err := s.ResolveArtifact(context.Background(), "go1.22.1", "linux", "amd64"); err == nil {
+			t.Fatal("expected an error when no verified hash is available")
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		proxy := newTestProxy(t, "", nil)
+		s := newTestGoProxySource(t, GoProxyConfig{GoProxy: proxy.URL, GoSumDB: vkey + " http://127.0.0.1:1", GoNoSumDB: "golang.org/toolchain"})
+		artifact, err := s.ResolveArtifact(context.Background(), "go1.22.1", "linux", "amd64")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if artifact.H1 != "" {
+			t.Errorf("H1 = %q, want no verification", artifact.H1)
+		}
+	})
+}
+
+func TestSumDBConfig(t *testing.T) {
+	db, ok, err := GoProxyConfig{}.sumDB()
+	if err != nil || !ok || db.name != "sum.golang.org" || db.url != "https://sum.golang.org" {
+		t.Errorf("default = %+v, %v, %v", db, ok, err)
+	}
+	db, ok, err = GoProxyConfig{GoSumDB: "sum.golang.google.cn"}.sumDB()
+	if err != nil || !ok || db.name != "sum.golang.org" || db.url != "https://sum.golang.google.cn" {
+		t.Errorf("sum.golang.google.cn = %+v, %v, %v", db, ok, err)
+	}
+	if _, _, err := (GoProxyConfig{GoSumDB: "sum.example.com"}).sumDB(); err == nil {
+		t.Error("expected an error for a database without a key")
+	}
+	if _, ok, _ := (GoProxyConfig{GoPrivate: "golang.org/*"}).sumDB(); ok {
+		t.Error("GOPRIVATE should disable the checksum database")
+	}
+}
@@ -0,0 +1,80 @@
+package version
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// maxHistoryEntries 历史记录最多保留的条数
+const maxHistoryEntries = 100
+
+type HistoryEntry struct {
+	Version  string    `json:"version"`
+	Previous string    `json:"previous,omitempty"`
+	Time     time.Time `json:"time"`
+	Dir      string    `json:"dir"`
+}
+
+// ReadHistory 读取版本切换历史，文件不存在时返回空列表
+func ReadHistory(historyFilePath string) ([]HistoryEntry, error) {
+	content, err := os.ReadFile(historyFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read the history file: %v", err)
+	}
+	if len(content) == 0 {
+		return nil, nil
+	}
+	var entries []HistoryEntry
+	if err := json.Unmarshal(content, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode the history file: %v", err)
+	}
+	return entries, nil
+}
+
+// AppendHistory 追加一条切换记录，只保留最近 maxHistoryEntries 条
+func AppendHistory(historyFilePath string, entry HistoryEntry) error {
+	entries, err := ReadHistory(historyFilePath)
+	if err != nil {
+		return err
+	}
+	entries = append(entries, entry)
+	if len(entries) > maxHistoryEntries {
+		entries = entries[len(entries)-maxHistoryEntries:]
+	}
+	content, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode the history: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(historyFilePath), 0755); err != nil {
+		return fmt.Errorf("failed to create the history file directory: %v", err)
+	}
+	if err := os.WriteFile(historyFilePath, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write the history file: %v", err)
+	}
+	return nil
+}
+
+// PreviousVersion 返回切换到当前版本之前使用的版本，
+// 没有记录时退化为历史中最近一个与当前版本不同的版本
+func PreviousVersion(entries []HistoryEntry, currentVersion string) (string, error) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Version == currentVersion {
+			if previous := entries[i].Previous; previous != "" && previous != currentVersion {
+				return previous, nil
+			}
+			break
+		}
+	}
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Version != currentVersion {
+			return entries[i].Version, nil
+		}
+	}
+	return "", fmt.Errorf("no previous version found in history")
+}
@@ -1,55 +1,234 @@
 package version
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/aide-cloud/gvm/pkg/dir"
 	"github.com/aide-cloud/gvm/pkg/download"
-	"github.com/aide-cloud/gvm/pkg/log"
 )
 
-func Install(cacheFilePath, sdkFilePath, downloadFileUrl string) error {
-	log.Info("checking cache file exists", "cacheFilePath", cacheFilePath)
-	exist, err := dir.CheckFileExists(cacheFilePath)
+// ProgressFunc 安装进度回调，参数形式与 log.Info 相同
+type ProgressFunc func(stage string, args ...any)
+
+// InstallTask 单个版本的安装参数
+type InstallTask struct {
+	Client          *http.Client
+	CacheFilePath   string
+	SdkFilePath     string
+	DownloadFileURL string
+	// Open 打开归档内容，为空时通过 Client 请求 DownloadFileURL
+	Open func(ctx context.Context) (io.ReadCloser, error)
+	// SHA256 期望的归档摘要，为空时不校验
+	SHA256 string
+	// H1 期望的模块 zip 哈希（h1: 格式），用于 GOPROXY 来源，为空时不校验
+	H1 string
+	// NoCache 为 true 时既不使用也不写入缓存归档
+	NoCache bool
+	// Connections 大于 1 时分段并发下载，下载完成并校验后再解压
+	Connections int
+}
+
+// Install 将归档解压到暂存目录，校验通过后整体重命名为 SdkFilePath，避免中途失败留下不完整的 sdk 目录。
+// 缓存中已有归档时从缓存解压，否则边下载边解压，启用缓存时同时写入缓存文件。
+// ctx 被取消时中止下载和解压，返回 ctx.Err() 并删除暂存目录和未完成的缓存文件
+func Install(ctx context.Context, task InstallTask, progress ProgressFunc) error {
+	stagingDir, err := os.MkdirTemp(filepath.Dir(task.SdkFilePath), ".staging-"+filepath.Base(task.SdkFilePath)+"-")
 	if err != nil {
-		return fmt.Errorf("failed to check cache file exists: %v", err)
+		return fmt.Errorf("failed to create staging directory: %v", err)
 	}
-	if !exist {
-		log.Info("cache file not exists, downloading file", "cacheFilePath", cacheFilePath)
+	defer os.RemoveAll(stagingDir)
 
-		log.Info("downloading file", "url", downloadFileUrl, "cacheFilePath", cacheFilePath)
-		if err := download.FetchFile(downloadFileUrl, cacheFilePath); err != nil {
-			return fmt.Errorf("failed to download file: %v", err)
+	cached := false
+	if !task.NoCache {
+		progress("checking cache file exists", "cacheFilePath", task.CacheFilePath)
+		if cached, err = dir.CheckFileExists(task.CacheFilePath); err != nil {
+			return fmt.Errorf("failed to check cache file exists: %v", err)
 		}
-		log.Info("downloaded file", "url", downloadFileUrl, "cacheFilePath", cacheFilePath)
 	}
-
-	log.Info("creating sdk directory", "path", sdkFilePath)
-	if err := os.MkdirAll(sdkFilePath, 0755); err != nil {
-		return fmt.Errorf("failed to create sdk directory: %v", err)
+	switch {
+	case cached:
+		err = extractCachedArchive(ctx, task, stagingDir, progress)
+	case task.Connections > 1 || task.isZip():
+		// zip 需要随机访问，无法边下载边解压
+		err = fetchArchive(ctx, task, stagingDir, progress)
+	default:
+		err = streamArchive(ctx, task, stagingDir, progress)
 	}
-
-	log.Info("extracting file", "cacheFilePath", cacheFilePath, "sdkFilePath", sdkFilePath)
-	if err := download.ExtractGoSdkTarGzFile(cacheFilePath, sdkFilePath); err != nil {
-		return fmt.Errorf("failed to extract tar.gz file: %v", err)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
 	}
-	log.Info("installed version", "sdkFilePath", sdkFilePath)
 
 	// 递归设置权限
-	binFilePath := filepath.Join(sdkFilePath, "bin")
+	binFilePath := filepath.Join(stagingDir, "bin")
 	if err := setPermissionsRecursively(binFilePath, 0755); err != nil {
 		return fmt.Errorf("failed to set permissions to bin directory: %v", err)
 	}
-	log.Info("set permissions to bin directory", "binFilePath", binFilePath)
-
 	// 设置 pkg/tool 目录的权限（Go 工具需要执行权限）
-	toolDirPath := filepath.Join(sdkFilePath, "pkg", "tool")
+	toolDirPath := filepath.Join(stagingDir, "pkg", "tool")
 	if err := setPermissionsRecursively(toolDirPath, 0755); err != nil {
 		return fmt.Errorf("failed to set permissions to tool directory: %v", err)
 	}
-	log.Info("set permissions to tool directory", "toolDirPath", toolDirPath)
+	if err := os.Chmod(stagingDir, 0755); err != nil {
+		return fmt.Errorf("failed to set permissions to staging directory: %v", err)
+	}
+
+	if err := os.Rename(stagingDir, task.SdkFilePath); err != nil {
+		return fmt.Errorf("failed to move staging directory into place: %v", err)
+	}
+	progress("moved sdk into place", "sdkFilePath", task.SdkFilePath)
+	return nil
+}
+
+// extractCachedArchive 校验缓存归档后解压到暂存目录，校验失败时删除缓存归档
+func extractCachedArchive(ctx context.Context, task InstallTask, stagingDir string, progress ProgressFunc) error {
+	if task.SHA256 != "" || task.H1 != "" {
+		progress("verifying cache file", "cacheFilePath", task.CacheFilePath)
+		expected, sum, err := task.checksum(task.CacheFilePath)
+		if err != nil {
+			return fmt.Errorf("failed to hash cache file: %v", err)
+		}
+		if sum != expected {
+			_ = os.Remove(task.CacheFilePath)
+			return fmt.Errorf("%w for cached %s: expected %s, got %s (removed)", ErrChecksumMismatch, task.CacheFilePath, expected, sum)
+		}
+		task.writeChecksumFile(task.CacheFilePath, sum, progress)
+	}
+	progress("extracting file", "cacheFilePath", task.CacheFilePath, "stagingDir", stagingDir)
+	return task.extract(ctx, task.CacheFilePath, stagingDir)
+}
+
+// fetchArchive 下载完整归档到临时文件（http 地址可分段并发下载），校验通过后解压，启用缓存时提交为缓存文件
+func fetchArchive(ctx context.Context, task InstallTask, stagingDir string, progress ProgressFunc) error {
+	partFilePath := task.CacheFilePath + ".part"
+	if task.NoCache {
+		partFilePath = stagingDir + ".part"
+	}
+	defer os.Remove(partFilePath)
+
+	progress("downloading file", "url", download.RedactURL(task.DownloadFileURL), "connections", task.Connections)
+	if err := task.fetch(ctx, partFilePath); err != nil {
+		return fmt.Errorf("failed to download file: %v", err)
+	}
+	sum := ""
+	if task.SHA256 != "" || task.H1 != "" {
+		expected, actual, err := task.checksum(partFilePath)
+		if err != nil {
+			return fmt.Errorf("failed to hash downloaded file: %v", err)
+		}
+		if actual != expected {
+			return fmt.Errorf("%w for %s: expected %s, got %s", ErrChecksumMismatch, download.RedactURL(task.DownloadFileURL), expected, actual)
+		}
+		sum = actual
+	}
+	progress("extracting file", "stagingDir", stagingDir)
+	if err := task.extract(ctx, partFilePath, stagingDir); err != nil {
+		return err
+	}
+	if !task.NoCache {
+		if err := os.Rename(partFilePath, task.CacheFilePath); err != nil {
+			return fmt.Errorf("failed to save cache file: %v", err)
+		}
+		progress("cached file", "cacheFilePath", task.CacheFilePath)
+		task.writeChecksumFile(task.CacheFilePath, sum, progress)
+	}
+	return nil
+}
+
+// streamArchive 单次读取下载内容完成摘要计算和解压，校验通过后才提交缓存文件
+func streamArchive(ctx context.Context, task InstallTask, stagingDir string, progress ProgressFunc) error {
+	partFilePath := ""
+	if !task.NoCache {
+		partFilePath = task.CacheFilePath + ".part"
+		defer os.Remove(partFilePath)
+	}
+	progress("downloading and extracting file", "url", download.RedactURL(task.DownloadFileURL), "stagingDir", stagingDir)
+	body, err := task.open(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to download and extract file: %v", err)
+	}
+	defer body.Close()
+	sum, err := download.ExtractGoSdkTarGzStream(ctx, body, stagingDir, partFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to download and extract file: %v", err)
+	}
+	if task.SHA256 != "" && sum != task.SHA256 {
+		return fmt.Errorf("%w for %s: expected %s, got %s", ErrChecksumMismatch, download.RedactURL(task.DownloadFileURL), task.SHA256, sum)
+	}
+	if partFilePath != "" {
+		if err := os.Rename(partFilePath, task.CacheFilePath); err != nil {
+			return fmt.Errorf("failed to save cache file: %v", err)
+		}
+		progress("cached file", "cacheFilePath", task.CacheFilePath)
+		task.writeChecksumFile(task.CacheFilePath, sum, progress)
+	}
+	return nil
+}
+
+// writeChecksumFile 缓存归档通过 SHA-256 校验后写入摘要文件，cache ls 和 mirror serve 据此认为归档已校验；
+// 没有期望的 SHA-256（只有模块 zip 的 h1 摘要）时不写入，写入失败不影响安装
+func (task InstallTask) writeChecksumFile(archivePath, sum string, progress ProgressFunc) {
+	if task.SHA256 == "" || task.H1 != "" || sum != task.SHA256 {
+		return
+	}
+	if err := os.WriteFile(checksumFilePath(archivePath), []byte(sum+"\n"), 0644); err != nil {
+		progress("failed to write checksum file", "error", err)
+	}
+}
+
+func (task InstallTask) open(ctx context.Context) (io.ReadCloser, error) {
+	if task.Open != nil {
+		return task.Open(ctx)
+	}
+	return download.Open(ctx, task.Client, task.DownloadFileURL)
+}
+
+// fetch 把归档保存到 destPath，http 地址按 Connections 分段并发下载，失败或取消时删除 destPath
+func (task InstallTask) fetch(ctx context.Context, destPath string) error {
+	if task.Connections > 1 && (strings.HasPrefix(task.DownloadFileURL, "http://") || strings.HasPrefix(task.DownloadFileURL, "https://")) {
+		return download.FetchFileChunked(ctx, task.Client, task.DownloadFileURL, destPath, task.Connections)
+	}
+	body, err := task.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return download.SaveFile(ctx, body, destPath)
+}
+
+func (task InstallTask) isZip() bool {
+	return strings.HasSuffix(task.CacheFilePath, ".zip")
+}
+
+// checksum 按归档类型计算文件摘要，返回期望值和实际值
+func (task InstallTask) checksum(path string) (string, string, error) {
+	if task.H1 != "" {
+		sum, err := download.HashModuleZip(path)
+		return task.H1, sum, err
+	}
+	sum, err := download.SHA256File(path)
+	return task.SHA256, sum, err
+}
+
+// extract 按归档类型解压到目标目录
+func (task InstallTask) extract(ctx context.Context, path, destPath string) error {
+	if task.isZip() {
+		if err := download.ExtractModuleZipFile(ctx, path, destPath); err != nil {
+			return fmt.Errorf("failed to extract zip file: %v", err)
+		}
+		return nil
+	}
+	if err := download.ExtractGoSdkTarGzFile(ctx, path, destPath); err != nil {
+		return fmt.Errorf("failed to extract tar.gz file: %v", err)
+	}
 	return nil
 }
 
@@ -0,0 +1,437 @@
+package version
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aide-cloud/gvm/pkg/dir"
+	"github.com/aide-cloud/gvm/pkg/download"
+	"github.com/aide-cloud/gvm/pkg/lock"
+)
+
+// mirrorHandler 以 go.dev 兼容的方式提供缓存目录中的归档：
+// /?mode=json 返回版本列表，/ 返回目录索引页，/<filename> 返回归档，/<filename>.sha256 返回摘要
+type mirrorHandler struct {
+	v *Version
+
+	mu sync.Mutex
+	// sums 按归档路径缓存摘要，归档大小或修改时间变化后重新计算
+	sums map[string]mirrorSum
+}
+
+type mirrorSum struct {
+	size    int64
+	modTime time.Time
+	sha256  string
+}
+
+// NewMirrorHandler 创建以缓存目录为内容的镜像 http.Handler
+func (v *Version) NewMirrorHandler() http.Handler {
+	return &mirrorHandler{v: v, sums: make(map[string]mirrorSum)}
+}
+
+// MirrorServe 在 addr 上提供缓存目录的镜像服务，其他 gvm 可以把 --origin-url 和 --download-url 指向它。
+// ctx 被取消时停止接受新连接，等待进行中的请求完成后返回
+func (v *Version) MirrorServe(ctx context.Context, addr string) error {
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           v.NewMirrorHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	shutdown := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		defer close(shutdown)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			v.logger.Warn("Failed to shut down mirror gracefully", "error", err)
+		}
+	})
+	defer stop()
+	v.logger.Info("serving mirror", "addr", addr, "cacheDir", v.cacheDir)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	<-shutdown
+	v.logger.Info("mirror stopped", "addr", addr)
+	return nil
+}
+
+func (h *mirrorHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name := strings.TrimPrefix(path.Clean(r.URL.Path), "/")
+	if name == "" {
+		if r.URL.Query().Get("mode") == "json" {
+			h.serveIndex(w, r.URL.Query().Get("include") == "all")
+			return
+		}
+		h.serveListing(w)
+		return
+	}
+	if strings.Contains(name, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	archiveName, isChecksum := strings.CutSuffix(name, ".sha256")
+	archives, err := h.v.listCacheArchives()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	i := slices.IndexFunc(archives, func(a cacheArchive) bool { return a.Filename == archiveName })
+	if i < 0 {
+		http.NotFound(w, r)
+		return
+	}
+	if !isChecksum {
+		h.v.logger.Info("serving archive", "filename", archiveName, "remote", r.RemoteAddr)
+		http.ServeFile(w, r, archives[i].Path)
+		return
+	}
+	sum, err := h.sha256(archives[i])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintln(w, sum)
+}
+
+// serveIndex 返回 go.dev 格式的版本列表，未指定 include=all 时只包含正式版
+func (h *mirrorHandler) serveIndex(w http.ResponseWriter, includeAll bool) {
+	originVersions, err := h.index()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !includeAll {
+		originVersions = slices.DeleteFunc(originVersions, func(o OriginVersion) bool { return !o.Stable })
+	}
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", " ")
+	_ = enc.Encode(originVersions)
+}
+
+// serveListing 返回简单的目录索引页，便于 --source mirror 和浏览器访问
+func (h *mirrorHandler) serveListing(w http.ResponseWriter) {
+	archives, err := h.v.listCacheArchives()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintln(w, "<pre>")
+	for _, a := range archives {
+		fmt.Fprintf(w, "<a href=\"%s\">%s</a>\n", html.EscapeString(a.Filename), html.EscapeString(a.Filename))
+	}
+	fmt.Fprintln(w, "</pre>")
+}
+
+// index 根据缓存目录中的归档构造版本列表，包含计算出的 SHA-256 和大小
+func (h *mirrorHandler) index() ([]OriginVersion, error) {
+	archives, err := h.v.listCacheArchives()
+	if err != nil {
+		return nil, err
+	}
+	byFilename := make(map[string]cacheArchive, len(archives))
+	filenames := make([]string, 0, len(archives))
+	for _, a := range archives {
+		byFilename[a.Filename] = a
+		filenames = append(filenames, a.Filename)
+	}
+	originVersions := originVersionsFromFilenames(filenames)
+	for _, o := range originVersions {
+		for i := range o.Files {
+			a := byFilename[o.Files[i].Filename]
+			sum, err := h.sha256(a)
+			if err != nil {
+				return nil, err
+			}
+			o.Files[i].SHA256 = sum
+			o.Files[i].Size = int(a.Size)
+		}
+	}
+	return originVersions, nil
+}
+
+// sha256 返回归档的摘要：已校验的归档直接使用摘要文件（install、cache verify、mirror sync 和 bundle import 校验通过后写入），
+// 否则计算摘要，结果按路径缓存。
+// 计算在锁外进行，一个大归档的哈希不会阻塞其他请求
+func (h *mirrorHandler) sha256(a cacheArchive) (string, error) {
+	h.mu.Lock()
+	s, ok := h.sums[a.Path]
+	h.mu.Unlock()
+	if ok && s.size == a.Size && s.modTime.Equal(a.ModTime) {
+		return s.sha256, nil
+	}
+	sum := ""
+	if a.isVerified() {
+		if content, err := os.ReadFile(checksumFilePath(a.Path)); err == nil {
+			sum = parseChecksumFile(content)
+		}
+	}
+	if sum == "" {
+		var err error
+		if sum, err = download.SHA256File(a.Path); err != nil {
+			return "", fmt.Errorf("failed to hash %s: %v", a.Filename, err)
+		}
+	}
+	h.mu.Lock()
+	h.sums[a.Path] = mirrorSum{size: a.Size, modTime: a.ModTime, sha256: sum}
+	h.mu.Unlock()
+	return sum, nil
+}
+
+// mirrorIndexFilename mirror sync 在目标目录中写入的 go.dev 格式版本列表
+const mirrorIndexFilename = "index.json"
+
+// Platform 归档的目标平台
+type Platform struct {
+	OS   string
+	Arch string
+}
+
+func (p Platform) String() string {
+	return p.OS + "/" + p.Arch
+}
+
+// ParsePlatforms 解析逗号分隔的 os/arch 列表
+func ParsePlatforms(s string) ([]Platform, error) {
+	var platforms []Platform
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		goos, goarch, ok := strings.Cut(p, "/")
+		if !ok || goos == "" || goarch == "" || strings.Contains(goarch, "/") {
+			return nil, fmt.Errorf("invalid platform %q, expected os/arch", p)
+		}
+		platforms = append(platforms, Platform{OS: goos, Arch: goarch})
+	}
+	if len(platforms) == 0 {
+		return nil, fmt.Errorf("no platforms given")
+	}
+	return platforms, nil
+}
+
+// resolveSyncVersions 约束表达式匹配源站中的正式版，否则按逗号分隔逐个解析
+func (v *Version) resolveSyncVersions(ctx context.Context, versions string) ([]string, error) {
+	if !IsConstraint(versions) {
+		var resolved []string
+		for _, target := range strings.Split(versions, ",") {
+			if target = strings.TrimSpace(target); target == "" {
+				continue
+			}
+			version, err := v.ResolveVersion(ctx, target, false)
+			if err != nil {
+				return nil, err
+			}
+			resolved = append(resolved, version)
+		}
+		return resolved, nil
+	}
+	constraint, err := ParseConstraint(versions)
+	if err != nil {
+		return nil, err
+	}
+	originVersions, err := v.OriginVersions(ctx, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch origin versions: %v", err)
+	}
+	var resolved []string
+	for _, o := range originVersions {
+		if o.Stable && constraint.Match(o.Version) {
+			resolved = append(resolved, o.Version)
+		}
+	}
+	return resolved, nil
+}
+
+// MirrorSync 把匹配的版本在各平台上的归档下载到 destDir，校验后写入 go.dev 格式的 index.json。
+// 已下载且有摘要文件的归档不会重新下载，因此可以反复执行来补齐缺失的归档
+func (v *Version) MirrorSync(ctx context.Context, versions string, platforms []Platform, destDir string) error {
+	targets, err := v.resolveSyncVersions(ctx, versions)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("%w: no versions match %s", ErrVersionNotFound, versions)
+	}
+	originVersions, err := v.OriginVersions(ctx, false)
+	if err != nil {
+		return fmt.Errorf("failed to fetch origin versions: %v", err)
+	}
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return fmt.Errorf("failed to create the mirror directory: %v", err)
+	}
+	syncLock, err := lock.Acquire(ctx, filepath.Join(destDir, ".sync.lock"), v.lockTimeout, v.logger)
+	if err != nil {
+		return err
+	}
+	defer syncLock.Release()
+
+	items := []MirrorSyncItem{}
+	var downloaded, upToDate, failed int
+	for _, version := range targets {
+		for _, platform := range platforms {
+			if _, ok := findArtifactFile(originVersions, version, platform.OS, platform.Arch); !ok {
+				v.logger.Info("not available, skipping", "version", version, "platform", platform)
+				continue
+			}
+			item := MirrorSyncItem{Version: version, Platform: platform.String()}
+			fetched, err := v.syncArtifact(ctx, version, platform, destDir, &item)
+			switch {
+			case err != nil:
+				failed++
+				item.Status = StatusFailed
+				item.Error = err.Error()
+				v.logger.Error("Failed to sync archive:", "version", version, "platform", platform, "error", err)
+			case fetched:
+				downloaded++
+				item.Status = StatusDownloaded
+			default:
+				upToDate++
+				item.Status = StatusUpToDate
+			}
+			items = append(items, item)
+		}
+	}
+	if err := writeMirrorIndex(destDir); err != nil {
+		return err
+	}
+	if !v.printResult(items) {
+		fmt.Printf("Synced: %d downloaded, %d up to date, %d failed\n", downloaded, upToDate, failed)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d archives failed to sync", failed)
+	}
+	return nil
+}
+
+// syncArtifact 下载并校验单个归档，已存在且有摘要文件时跳过，返回是否进行了下载
+func (v *Version) syncArtifact(ctx context.Context, version string, platform Platform, destDir string, item *MirrorSyncItem) (bool, error) {
+	artifact, err := v.source.ResolveArtifact(ctx, version, platform.OS, platform.Arch)
+	if err != nil {
+		return false, err
+	}
+	destPath := filepath.Join(destDir, artifact.Filename)
+	item.Path = destPath
+	if mirrorArchiveSynced(destPath) {
+		return false, nil
+	}
+
+	start := time.Now()
+	sum, err := v.fetchArtifact(ctx, artifact, destPath)
+	if err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(checksumFilePath(destPath), []byte(sum+"\n"), 0644); err != nil {
+		return false, fmt.Errorf("failed to write checksum file: %v", err)
+	}
+	v.logger.Info("synced file", "version", version, "platform", platform, "path", destPath, "duration", time.Since(start).Round(time.Millisecond))
+	return true, nil
+}
+
+// fetchArtifact 下载归档到 destPath，按来源提供的摘要校验后才放到最终位置，返回归档的 SHA-256
+func (v *Version) fetchArtifact(ctx context.Context, artifact Artifact, destPath string) (string, error) {
+	task := InstallTask{
+		Client:          v.httpClient,
+		CacheFilePath:   destPath,
+		DownloadFileURL: artifact.URL,
+		Open: func(ctx context.Context) (io.ReadCloser, error) {
+			return v.source.OpenArtifact(ctx, artifact)
+		},
+		SHA256:      artifact.SHA256,
+		H1:          artifact.H1,
+		Connections: v.connections,
+	}
+	partFilePath := destPath + ".part"
+	defer os.Remove(partFilePath)
+	v.logger.Debug("downloading file", "url", download.RedactURL(artifact.URL))
+	if err := task.fetch(ctx, partFilePath); err != nil {
+		return "", fmt.Errorf("failed to download file: %v", err)
+	}
+	if task.SHA256 != "" || task.H1 != "" {
+		expected, sum, err := task.checksum(partFilePath)
+		if err != nil {
+			return "", fmt.Errorf("failed to hash downloaded file: %v", err)
+		}
+		if sum != expected {
+			return "", fmt.Errorf("%w for %s: expected %s, got %s", ErrChecksumMismatch, download.RedactURL(artifact.URL), expected, sum)
+		}
+	} else {
+		v.logger.Warn("No checksum found in origin metadata, skipping verification", "filename", artifact.Filename)
+	}
+	sum, err := download.SHA256File(partFilePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash downloaded file: %v", err)
+	}
+	if err := os.Rename(partFilePath, destPath); err != nil {
+		return "", fmt.Errorf("failed to save file: %v", err)
+	}
+	return sum, nil
+}
+
+// mirrorArchiveSynced 归档存在且摘要文件不早于归档时认为已同步
+func mirrorArchiveSynced(archivePath string) bool {
+	archiveInfo, err := os.Stat(archivePath)
+	if err != nil {
+		return false
+	}
+	sumInfo, err := os.Stat(checksumFilePath(archivePath))
+	return err == nil && !sumInfo.ModTime().Before(archiveInfo.ModTime())
+}
+
+// writeMirrorIndex 根据目录中已同步的归档写入 go.dev 格式的 index.json
+func writeMirrorIndex(destDir string) error {
+	dis, err := os.ReadDir(destDir)
+	if err != nil {
+		return fmt.Errorf("failed to read the mirror directory: %v", err)
+	}
+	var filenames []string
+	for _, di := range dis {
+		if !di.IsDir() && mirrorArchiveSynced(filepath.Join(destDir, di.Name())) {
+			filenames = append(filenames, di.Name())
+		}
+	}
+	originVersions := originVersionsFromFilenames(filenames)
+	for _, o := range originVersions {
+		for i := range o.Files {
+			archivePath := filepath.Join(destDir, o.Files[i].Filename)
+			content, err := os.ReadFile(checksumFilePath(archivePath))
+			if err != nil {
+				return fmt.Errorf("failed to read checksum file: %v", err)
+			}
+			info, err := os.Stat(archivePath)
+			if err != nil {
+				return fmt.Errorf("failed to stat archive: %v", err)
+			}
+			o.Files[i].SHA256 = parseChecksumFile(content)
+			o.Files[i].Size = int(info.Size())
+		}
+	}
+	content, err := json.MarshalIndent(originVersions, "", " ")
+	if err != nil {
+		return fmt.Errorf("failed to encode the mirror index: %v", err)
+	}
+	if err := dir.WriteFileAtomic(filepath.Join(destDir, mirrorIndexFilename), append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write the mirror index: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package version
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestMirror 把 version 安装到一个新的 gvm 中，再以其缓存目录提供镜像服务
+func newTestMirror(t *testing.T, version string) (*Version, *httptest.Server) {
+	t.Helper()
+	fake := &fakeSource{archives: map[string][]byte{version: buildSdkArchive(t, version)}}
+	upstream := newTestVersion(t, WithSource(fake))
+	if _, err := upstream.InstallVersion(context.Background(), version, false, func(string, ...any) {}); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(upstream.NewMirrorHandler())
+	t.Cleanup(srv.Close)
+	return upstream, srv
+}
+
+func TestMirrorServeInstall(t *testing.T) {
+	_, srv := newTestMirror(t, "go1.22.1")
+	tests := []struct {
+		name string
+		opts []VersionOption
+	}{
+		{name: "go.dev", opts: []VersionOption{WithOriginURL(srv.URL + "/?mode=json&include=all"), WithDownloadURL(srv.URL + "/")}},
+		{name: "mirror", opts: []VersionOption{WithSourceName(SourceMirror), WithDownloadURL(srv.URL + "/")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newTestVersion(t, tt.opts...)
+			version, err := v.ResolveVersion(context.Background(), "latest", false)
+			if err != nil || version != "go1.22.1" {
+				t.Fatalf("ResolveVersion = %s, %v", version, err)
+			}
+			task, err := v.installTask(context.Background(), version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if task.SHA256 == "" {
+				t.Error("mirror did not provide a checksum")
+			}
+			if _, err := v.InstallVersion(context.Background(), version, false, func(string, ...any) {}); err != nil {
+				t.Fatal(err)
+			}
+			content, err := os.ReadFile(filepath.Join(v.SdkFilePath(version), "VERSION"))
+			if err != nil || string(content) != version {
+				t.Fatalf("VERSION = %q, %v", content, err)
+			}
+		})
+	}
+}
+
+func TestMirrorHandlerUsesVerifiedChecksum(t *testing.T) {
+	upstream, _ := newTestMirror(t, "go1.22.1")
+	archives, err := upstream.listCacheArchives()
+	if err != nil || len(archives) != 1 {
+		t.Fatalf("archives = %+v, %v", archives, err)
+	}
+	a := archives[0]
+	if !a.isVerified() {
+		t.Fatal("install did not mark the archive as verified")
+	}
+	content, err := os.ReadFile(a.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := upstream.NewMirrorHandler().(*mirrorHandler)
+	sum, err := h.sha256(a)
+	if err != nil || sum != sha256Hex(content) {
+		t.Fatalf("sha256 = %s, %v, want %s", sum, err, sha256Hex(content))
+	}
+	if _, ok := h.sums[a.Path]; !ok {
+		t.Error("checksum was not cached")
+	}
+
+	// 摘要文件中的值说明没有重新计算归档的摘要
+	recorded := strings.Repeat("ab", 32)
+	if err := os.WriteFile(checksumFilePath(a.Path), []byte(recorded+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	h = upstream.NewMirrorHandler().(*mirrorHandler)
+	if sum, err := h.sha256(a); err != nil || sum != recorded {
+		t.Errorf("sha256 = %s, %v, want the recorded %s", sum, err, recorded)
+	}
+}
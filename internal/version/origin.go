@@ -1,13 +1,20 @@
 package version
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
 
+	"github.com/aide-cloud/gvm/pkg/dir"
+	"github.com/aide-cloud/gvm/pkg/download"
+	"github.com/aide-cloud/gvm/pkg/lock"
 	"github.com/aide-cloud/gvm/pkg/log"
 )
 
@@ -27,48 +34,192 @@ type File struct {
 	Kind     string `json:"kind"`
 }
 
-func FetchOriginVersions(originURL, versionFilePath string, forceUpdate bool) ([]OriginVersion, error) {
-	if _, err := os.Stat(versionFilePath); err == nil && !forceUpdate {
-		var originVersions []OriginVersion
-		content, err := os.ReadFile(versionFilePath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read the version file: %v", err)
+// originCacheSchemaVersion 版本缓存文件格式的版本号，格式变化时递增使旧缓存失效
+const originCacheSchemaVersion = 1
+
+// originCache 版本缓存文件的内容，记录来源地址和抓取元数据
+type originCache struct {
+	SchemaVersion int             `json:"schema_version"`
+	SourceURL     string          `json:"source_url"`
+	FetchedAt     time.Time       `json:"fetched_at"`
+	ETag          string          `json:"etag,omitempty"`
+	LastModified  string          `json:"last_modified,omitempty"`
+	Versions      []OriginVersion `json:"versions"`
+}
+
+var sha256Regex = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// validateOriginVersions 校验版本列表中的每一项，避免使用不完整或被篡改的数据
+func validateOriginVersions(originVersions []OriginVersion) error {
+	if len(originVersions) == 0 {
+		return fmt.Errorf("no versions found")
+	}
+	for _, o := range originVersions {
+		if !strings.HasPrefix(o.Version, "go") {
+			return fmt.Errorf("invalid version %q", o.Version)
 		}
-		if len(content) > 0 {
-			if err = json.Unmarshal(content, &originVersions); err != nil {
-				_ = os.Remove(versionFilePath)
+		for _, f := range o.Files {
+			if f.Filename == "" || strings.ContainsAny(f.Filename, "/\\") {
+				return fmt.Errorf("invalid filename %q in version %s", f.Filename, o.Version)
+			}
+			if f.SHA256 != "" && !sha256Regex.MatchString(f.SHA256) {
+				return fmt.Errorf("invalid sha256 %q for %s", f.SHA256, f.Filename)
 			}
-		}
-		if len(originVersions) > 0 {
-			return originVersions, nil
 		}
 	}
+	return nil
+}
 
-	log.Info("fetching origin versions", "url", originURL)
+// readOriginCache 读取本地版本缓存，缓存不存在、格式版本不符、来源地址不同或内容无效时返回 nil
+func readOriginCache(versionFilePath, originURL string) *originCache {
+	content, err := os.ReadFile(versionFilePath)
+	if err != nil {
+		return nil
+	}
+	var cache originCache
+	if err := json.Unmarshal(content, &cache); err != nil {
+		return nil
+	}
+	if cache.SchemaVersion != originCacheSchemaVersion || cache.SourceURL != originURL {
+		return nil
+	}
+	if err := validateOriginVersions(cache.Versions); err != nil {
+		return nil
+	}
+	return &cache
+}
 
-	resp, err := http.Get(originURL)
+// writeOriginCache 原子地写入版本缓存，并发的 gvm 进程不会读到写了一半的文件
+func writeOriginCache(versionFilePath string, cache *originCache) error {
+	cache.SchemaVersion = originCacheSchemaVersion
+	content, err := json.Marshal(cache)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch the webpage: %v", err)
+		return fmt.Errorf("failed to encode the version file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(versionFilePath), 0755); err != nil {
+		return fmt.Errorf("failed to create the version file directory: %v", err)
+	}
+	if err := dir.WriteFileAtomic(versionFilePath, append(content, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write the version file: %v", err)
+	}
+	return nil
+}
+
+// decodeOriginVersions 解析 go.dev 格式的 JSON 版本列表
+func decodeOriginVersions(content []byte) ([]OriginVersion, error) {
+	var originVersions []OriginVersion
+	if err := json.Unmarshal(content, &originVersions); err != nil {
+		return nil, err
+	}
+	return originVersions, nil
+}
+
+// originCacheFile 版本列表缓存文件，多个进程通过文件锁串行刷新
+type originCacheFile struct {
+	path        string
+	ttl         time.Duration
+	lockTimeout time.Duration
+	logger      log.Logger
+}
+
+// fetch 持有缓存锁获取 sourceURL 的版本列表，避免多个进程同时刷新缓存
+func (c originCacheFile) fetch(ctx context.Context, client *http.Client, sourceURL string, forceUpdate bool, decode func([]byte) ([]OriginVersion, error)) ([]OriginVersion, error) {
+	cacheLock, err := lock.Acquire(ctx, c.path+".lock", c.lockTimeout, c.logger)
+	if err != nil {
+		return nil, err
+	}
+	defer cacheLock.Release()
+	return fetchOriginCache(ctx, client, sourceURL, c.path, c.ttl, forceUpdate, decode, c.logger)
+}
+
+// fetchOriginCache 按缓存策略获取 originURL 的版本列表，decode 负责把响应内容转换为版本列表
+func fetchOriginCache(ctx context.Context, client *http.Client, originURL, versionFilePath string, ttl time.Duration, forceUpdate bool, decode func([]byte) ([]OriginVersion, error), logger log.Logger) ([]OriginVersion, error) {
+	cache := readOriginCache(versionFilePath, originURL)
+	if cache != nil && !forceUpdate && time.Since(cache.FetchedAt) < ttl {
+		return cache.Versions, nil
+	}
+
+	logger.Debug("fetching origin versions", "url", download.RedactURL(originURL))
+	start := time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, originURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the request: %v", err)
+	}
+	if cache != nil && !forceUpdate {
+		if cache.ETag != "" {
+			req.Header.Set("If-None-Match", cache.ETag)
+		}
+		if cache.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cache.LastModified)
+		}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		// 被取消时直接返回，不退回到过期缓存
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return staleOriginVersions(cache, fmt.Errorf("failed to fetch the webpage: %v", err), logger)
 	}
 	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cache != nil {
+		cache.FetchedAt = time.Now()
+		if err := writeOriginCache(versionFilePath, cache); err != nil {
+			return nil, err
+		}
+		logger.Debug("origin versions not modified", "versionFilePath", versionFilePath, "duration", time.Since(start).Round(time.Millisecond))
+		return cache.Versions, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return staleOriginVersions(cache, fmt.Errorf("failed to fetch the webpage: %w", &download.StatusError{StatusCode: resp.StatusCode, Status: resp.Status}), logger)
+	}
 	content, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read the response body: %v", err)
+		return staleOriginVersions(cache, fmt.Errorf("failed to read the response body: %v", err), logger)
 	}
 
-	var originVersions []OriginVersion
-	err = json.Unmarshal(content, &originVersions)
+	// 返回了错误页面或不完整的列表时同样退回到过期缓存
+	originVersions, err := decode(content)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode the response: %v", err)
+		return staleOriginVersions(cache, fmt.Errorf("failed to decode the response: %v", err), logger)
 	}
-
-	if err := os.MkdirAll(filepath.Dir(versionFilePath), 0755); err != nil {
-		return nil, fmt.Errorf("failed to create the version file directory: %v", err)
+	if err := validateOriginVersions(originVersions); err != nil {
+		return staleOriginVersions(cache, fmt.Errorf("invalid origin versions: %v", err), logger)
 	}
 
-	if err := os.WriteFile(versionFilePath, append(content, '\n'), 0644); err != nil {
-		return nil, fmt.Errorf("failed to write the version file: %v", err)
+	cache = &originCache{
+		SourceURL:    originURL,
+		FetchedAt:    time.Now(),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Versions:     originVersions,
+	}
+	if err := writeOriginCache(versionFilePath, cache); err != nil {
+		return nil, err
 	}
-	log.Info("fetched origin versions", "versionFilePath", versionFilePath)
+	logger.Debug("fetched origin versions", "versionFilePath", versionFilePath, "duration", time.Since(start).Round(time.Millisecond))
 	return originVersions, nil
 }
+
+// staleOriginVersions 请求失败时如果存在缓存则带警告地返回过期缓存
+func staleOriginVersions(cache *originCache, err error, logger log.Logger) ([]OriginVersion, error) {
+	if cache == nil {
+		return nil, err
+	}
+	logger.Warn("Failed to refresh origin versions, using stale cache", "fetchedAt", cache.FetchedAt, "error", err)
+	return cache.Versions, nil
+}
+
+// findOriginFile 在源站版本列表中查找指定文件名的下载文件
+func findOriginFile(originVersions []OriginVersion, filename string) (File, bool) {
+	for _, o := range originVersions {
+		for _, f := range o.Files {
+			if f.Filename == filename {
+				return f, true
+			}
+		}
+	}
+	return File{}, false
+}
@@ -0,0 +1,50 @@
+package version
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aide-cloud/gvm/pkg/log"
+)
+
+func TestFetchOriginCacheFallsBackOnInvalidBody(t *testing.T) {
+	stale := []OriginVersion{{Version: "go1.22.1", Stable: true}}
+	for name, body := range map[string]string{
+		"error page": "<html>maintenance</html>",
+		"empty list": "[]",
+		"bad file":   `[{"version":"go1.22.2","files":[{"filename":"../go.tar.gz"}]}]`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, body)
+			}))
+			t.Cleanup(srv.Close)
+			versionFilePath := filepath.Join(t.TempDir(), "versions.json")
+
+			if _, err := fetchOriginCache(context.Background(), srv.Client(), srv.URL, versionFilePath, time.Hour, false, decodeOriginVersions, log.Default()); err == nil {
+				t.Fatal("expected an error without a cache to fall back to")
+			}
+
+			cache := &originCache{SourceURL: srv.URL, FetchedAt: time.Now().Add(-2 * time.Hour), Versions: stale}
+			if err := writeOriginCache(versionFilePath, cache); err != nil {
+				t.Fatal(err)
+			}
+			got, err := fetchOriginCache(context.Background(), srv.Client(), srv.URL, versionFilePath, time.Hour, false, decodeOriginVersions, log.Default())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 1 || got[0].Version != "go1.22.1" {
+				t.Errorf("got %+v, want the stale cache", got)
+			}
+			// 无效的响应不能覆盖缓存
+			if cache := readOriginCache(versionFilePath, srv.URL); cache == nil || len(cache.Versions) != 1 {
+				t.Errorf("cache was overwritten: %+v", cache)
+			}
+		})
+	}
+}
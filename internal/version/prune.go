@@ -0,0 +1,136 @@
+package version
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"slices"
+
+	"github.com/aide-cloud/gvm/pkg/dir"
+)
+
+// planPrune 计算需要清理的版本：keepLatestPatch 时每个次版本线保留最新的 keep 个补丁版本，
+// 否则整体保留最新的 keep 个版本；protected 中的版本以及无法解析的版本始终保留
+func planPrune(installed []string, protected map[string]bool, keepLatestPatch bool, keep int) []string {
+	groups := make(map[string][]string)
+	for _, version := range installed {
+		gv, err := parseGoVersion(version)
+		if err != nil {
+			continue
+		}
+		line := ""
+		if keepLatestPatch {
+			line = gv.minorLine()
+		}
+		groups[line] = append(groups[line], version)
+	}
+	var remove []string
+	for _, versions := range groups {
+		slices.SortFunc(versions, func(a, b string) int {
+			return CompareVersions(b, a)
+		})
+		for _, version := range versions[min(keep, len(versions)):] {
+			if !protected[version] {
+				remove = append(remove, version)
+			}
+		}
+	}
+	slices.SortFunc(remove, CompareVersions)
+	return remove
+}
+
+func (v *Version) Prune(ctx context.Context, keepLatestPatch bool, keep int, isDryRun bool) error {
+	if keep < 0 {
+		return fmt.Errorf("invalid keep number: %d", keep)
+	}
+	installed, err := FetchLocalVersions(v.sdkDir)
+	if err != nil {
+		return err
+	}
+	aliases, err := v.versionAliases(ctx)
+	if err != nil {
+		// 无法确定别名指向的版本时不清理，避免删除别名正在使用的版本
+		return fmt.Errorf("refusing to prune: %w", err)
+	}
+	protected := map[string]bool{v.CurrentVersion(): true}
+	for version := range aliases {
+		protected[version] = true
+	}
+
+	versions := planPrune(installed, protected, keepLatestPatch, keep)
+	var archives []cacheArchive
+	// --archive-dir 指向缓存目录时，其中的归档由用户自行管理
+	if !sameDir(v.archiveDir, v.cacheDir) {
+		cached, err := v.listCacheArchives()
+		if err != nil {
+			return err
+		}
+		archives = pruneArchives(cached, versions, installed, protected, runtime.GOOS, runtime.GOARCH)
+	}
+	result := PruneResult{Items: []PruneItem{}, DryRun: isDryRun}
+	if len(versions) == 0 && len(archives) == 0 {
+		v.logger.Info("Nothing to prune")
+		v.printResult(result)
+		return nil
+	}
+
+	out := v.textOut()
+	for _, version := range versions {
+		size, _ := dir.Size(v.SdkFilePath(version))
+		result.Reclaimed += size
+		result.Items = append(result.Items, PruneItem{Kind: PruneKindSdk, Version: version, Path: v.SdkFilePath(version), Size: size})
+		fmt.Fprintf(out, "remove sdk      %-12s %10s  %s\n", version, dir.FormatSize(size), v.SdkFilePath(version))
+	}
+	for _, archive := range archives {
+		result.Reclaimed += archive.Size
+		result.Items = append(result.Items, PruneItem{Kind: PruneKindArchive, Version: archive.Version, Path: archive.Path, Size: archive.Size})
+		fmt.Fprintf(out, "remove archive  %-12s %10s  %s\n", archive.Version, dir.FormatSize(archive.Size), archive.Path)
+	}
+	fmt.Fprintf(out, "Reclaimed: %s\n", dir.FormatSize(result.Reclaimed))
+	if isDryRun {
+		v.printResult(result)
+		return nil
+	}
+
+	for _, version := range versions {
+		if err := v.UninstallVersion(ctx, version); err != nil {
+			return fmt.Errorf("failed to uninstall %s: %w", version, err)
+		}
+	}
+	for _, archive := range archives {
+		if err := v.removeCacheArchive(ctx, archive); err != nil {
+			return fmt.Errorf("failed to remove archive: %w", err)
+		}
+		v.logger.Info("removed archive", "path", archive.Path)
+	}
+	v.removeStaleLocks(ctx)
+	v.printResult(result)
+	return nil
+}
+
+// pruneArchives 返回随版本一起清理的缓存归档：本次清理的版本以及不再安装的版本在当前平台的安装归档，
+// 受保护版本的归档以及其他平台的归档不会被清理
+func pruneArchives(archives []cacheArchive, versions, installed []string, protected map[string]bool, goos, goarch string) []cacheArchive {
+	var result []cacheArchive
+	for _, a := range archives {
+		if a.OS != goos || a.Arch != goarch {
+			continue
+		}
+		orphan := !slices.Contains(installed, a.Version) && !protected[a.Version]
+		if orphan || slices.Contains(versions, a.Version) {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// sameDir 判断两个路径是否指向同一个目录，任一为空时返回 false
+func sameDir(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
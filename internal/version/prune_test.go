@@ -0,0 +1,71 @@
+package version
+
+import (
+	"context"
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestPlanPrune(t *testing.T) {
+	installed := []string{"go1.20.14", "go1.21.0", "go1.21.5", "go1.22.0", "go1.22.1"}
+	protected := map[string]bool{"go1.20.14": true}
+
+	got := planPrune(installed, protected, false, 2)
+	if want := []string{"go1.21.0", "go1.21.5"}; !slices.Equal(got, want) {
+		t.Errorf("keep 2 = %v, want %v", got, want)
+	}
+	got = planPrune(installed, protected, true, 1)
+	if want := []string{"go1.21.0", "go1.22.0"}; !slices.Equal(got, want) {
+		t.Errorf("keep latest patch = %v, want %v", got, want)
+	}
+}
+
+func TestPruneArchives(t *testing.T) {
+	archives := []cacheArchive{
+		{Filename: "go1.21.0.linux-amd64.tar.gz", Version: "go1.21.0", OS: "linux", Arch: "amd64"},
+		{Filename: "go1.21.0.darwin-arm64.tar.gz", Version: "go1.21.0", OS: "darwin", Arch: "arm64"},
+		// 已经卸载的版本留下的归档
+		{Filename: "go1.19.0.linux-amd64.tar.gz", Version: "go1.19.0", OS: "linux", Arch: "amd64"},
+		// 被别名引用而保留的版本
+		{Filename: "go1.22.1.linux-amd64.tar.gz", Version: "go1.22.1", OS: "linux", Arch: "amd64"},
+		// 已安装且不清理的版本
+		{Filename: "go1.22.0.linux-amd64.tar.gz", Version: "go1.22.0", OS: "linux", Arch: "amd64"},
+	}
+	installed := []string{"go1.21.0", "go1.22.0"}
+	protected := map[string]bool{"go1.22.1": true}
+	var got []string
+	for _, a := range pruneArchives(archives, []string{"go1.21.0"}, installed, protected, "linux", "amd64") {
+		got = append(got, a.Filename)
+	}
+	if want := []string{"go1.21.0.linux-amd64.tar.gz", "go1.19.0.linux-amd64.tar.gz"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	got = nil
+	for _, a := range pruneArchives(archives, nil, installed, protected, "linux", "amd64") {
+		got = append(got, a.Filename)
+	}
+	if want := []string{"go1.19.0.linux-amd64.tar.gz"}; !slices.Equal(got, want) {
+		t.Errorf("got %v, want only the orphaned archive when no version is removed", got)
+	}
+}
+
+func TestPruneRefusesUnresolvedAlias(t *testing.T) {
+	v := newTestVersion(t, WithSource(&fakeSource{}))
+	for _, version := range []string{"go1.21.0", "go1.22.0"} {
+		if err := os.MkdirAll(v.SdkFilePath(version), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := WriteAliases(v.aliasFilePath(), map[string]Alias{"next": {Target: "1.21"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Prune(context.Background(), false, 0, false); err == nil {
+		t.Fatal("expected prune to fail when an alias cannot be resolved")
+	}
+	for _, version := range []string{"go1.21.0", "go1.22.0"} {
+		if _, err := os.Stat(v.SdkFilePath(version)); err != nil {
+			t.Errorf("%s was removed: %v", version, err)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package version
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/aide-cloud/gvm/pkg/output"
+)
+
+// 以下类型是 --output json|yaml 的输出结构，字段名是对外承诺的格式，只增不改
+
+// ListItem gvm list 的一项
+type ListItem struct {
+	Version string `json:"version" yaml:"version"`
+	Stable  bool   `json:"stable" yaml:"stable"`
+	// Available 当前平台是否有可下载的归档
+	Available bool `json:"available" yaml:"available"`
+	Installed bool `json:"installed" yaml:"installed"`
+	// Size 当前平台归档的字节数，未知时为 0
+	Size int64 `json:"size" yaml:"size"`
+}
+
+// LsItem gvm ls 的一项
+type LsItem struct {
+	Version string `json:"version" yaml:"version"`
+	Path    string `json:"path" yaml:"path"`
+	Active  bool   `json:"active" yaml:"active"`
+	// Size sdk 目录的字节数
+	Size        int64     `json:"size" yaml:"size"`
+	InstalledAt time.Time `json:"installed_at" yaml:"installed_at"`
+	Aliases     []string  `json:"aliases" yaml:"aliases"`
+}
+
+const (
+	StatusInstalled        = "installed"
+	StatusAlreadyInstalled = "already_installed"
+	StatusUninstalled      = "uninstalled"
+	StatusDownloaded       = "downloaded"
+	StatusUpToDate         = "up_to_date"
+	StatusFailed           = "failed"
+)
+
+// InstallResult gvm install 中一个版本的结果
+type InstallResult struct {
+	// Target 命令行中给出的版本参数
+	Target  string `json:"target" yaml:"target"`
+	Version string `json:"version" yaml:"version"`
+	Path    string `json:"path" yaml:"path"`
+	// Status 为 installed、already_installed 或 failed
+	Status     string `json:"status" yaml:"status"`
+	DurationMs int64  `json:"duration_ms" yaml:"duration_ms"`
+	Error      string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// UseResult gvm use 的结果
+type UseResult struct {
+	Version  string `json:"version" yaml:"version"`
+	Previous string `json:"previous" yaml:"previous"`
+	// GOROOT 切换后的 GOROOT
+	GOROOT string `json:"goroot" yaml:"goroot"`
+	// ShellConfig 写入了 GOROOT 的 shell 配置文件
+	ShellConfig string `json:"shell_config" yaml:"shell_config"`
+	// Installed 切换前是否先安装了该版本
+	Installed bool `json:"installed" yaml:"installed"`
+}
+
+// UninstallResult gvm uninstall 中一个版本的结果
+type UninstallResult struct {
+	Version string `json:"version" yaml:"version"`
+	Path    string `json:"path" yaml:"path"`
+	Size    int64  `json:"size" yaml:"size"`
+	// Status 为 uninstalled 或 failed
+	Status string `json:"status" yaml:"status"`
+	Error  string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+const (
+	PruneKindSdk     = "sdk"
+	PruneKindArchive = "archive"
+)
+
+// PruneItem gvm prune 清理的一项
+type PruneItem struct {
+	// Kind 为 sdk 或 archive
+	Kind    string `json:"kind" yaml:"kind"`
+	Version string `json:"version" yaml:"version"`
+	Path    string `json:"path" yaml:"path"`
+	Size    int64  `json:"size" yaml:"size"`
+}
+
+// PruneResult gvm prune 的结果
+type PruneResult struct {
+	Items []PruneItem `json:"items" yaml:"items"`
+	// Reclaimed 清理的总字节数，DryRun 时为预计值
+	Reclaimed int64 `json:"reclaimed" yaml:"reclaimed"`
+	DryRun    bool  `json:"dry_run" yaml:"dry_run"`
+}
+
+// HistoryItem gvm history 的一项
+type HistoryItem struct {
+	Time    time.Time `json:"time" yaml:"time"`
+	Version string    `json:"version" yaml:"version"`
+	// Dir 切换时所在的目录
+	Dir string `json:"dir" yaml:"dir"`
+}
+
+// AliasItem gvm alias ls 的一项
+type AliasItem struct {
+	Name   string `json:"name" yaml:"name"`
+	Target string `json:"target" yaml:"target"`
+	Frozen bool   `json:"frozen" yaml:"frozen"`
+	// Version 别名当前解析到的版本，无法解析时为空
+	Version string `json:"version" yaml:"version"`
+}
+
+// CacheItem gvm cache ls 的一项
+type CacheItem struct {
+	Filename string    `json:"filename" yaml:"filename"`
+	Path     string    `json:"path" yaml:"path"`
+	Version  string    `json:"version" yaml:"version"`
+	OS       string    `json:"os" yaml:"os"`
+	Arch     string    `json:"arch" yaml:"arch"`
+	Size     int64     `json:"size" yaml:"size"`
+	ModTime  time.Time `json:"mod_time" yaml:"mod_time"`
+	Verified bool      `json:"verified" yaml:"verified"`
+}
+
+// CacheCleanResult gvm cache clean 的结果
+type CacheCleanResult struct {
+	Removed   []CacheItem `json:"removed" yaml:"removed"`
+	Reclaimed int64       `json:"reclaimed" yaml:"reclaimed"`
+}
+
+const (
+	VerifyOK       = "ok"
+	VerifyMismatch = "mismatch"
+	VerifyUnknown  = "unknown"
+)
+
+// CacheVerifyItem gvm cache verify 的一项
+type CacheVerifyItem struct {
+	Filename string `json:"filename" yaml:"filename"`
+	// Status 为 ok、mismatch 或 unknown（源站元数据中没有摘要）
+	Status   string `json:"status" yaml:"status"`
+	Expected string `json:"expected,omitempty" yaml:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty" yaml:"actual,omitempty"`
+	Error    string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// CachePathResult gvm cache path 的结果
+type CachePathResult struct {
+	Path string `json:"path" yaml:"path"`
+}
+
+// MirrorSyncItem gvm mirror sync 中一个归档的结果
+type MirrorSyncItem struct {
+	Version  string `json:"version" yaml:"version"`
+	Platform string `json:"platform" yaml:"platform"`
+	Path     string `json:"path" yaml:"path"`
+	// Status 为 downloaded、up_to_date 或 failed
+	Status string `json:"status" yaml:"status"`
+	Error  string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// BundleResult gvm bundle create 和 gvm bundle import 的结果
+type BundleResult struct {
+	Bundle   string   `json:"bundle" yaml:"bundle"`
+	Versions []string `json:"versions" yaml:"versions"`
+	// Archives 打包或导入的归档文件名
+	Archives []string `json:"archives" yaml:"archives"`
+}
+
+// textOut 人类可读文本的输出位置，结构化输出时改为 stderr，保证 stdout 只有结构化结果
+func (v *Version) textOut() io.Writer {
+	if v.output.IsStructured() {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// printResult 结构化输出时打印结果，返回 false 表示调用方需要自行输出文本
+func (v *Version) printResult(result any) bool {
+	if !v.output.IsStructured() {
+		return false
+	}
+	if err := output.Print(os.Stdout, v.output, result); err != nil {
+		v.logger.Error("Failed to print result:", "error", err)
+	}
+	return true
+}
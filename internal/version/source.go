@@ -0,0 +1,286 @@
+package version
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/aide-cloud/gvm/pkg/download"
+	"github.com/aide-cloud/gvm/pkg/log"
+)
+
+const (
+	// SourceGoDev 从 go.dev 的 JSON 版本列表获取版本，从 downloadURL 下载 tar.gz
+	SourceGoDev = "go.dev"
+	// SourceGoProxy 从 GOPROXY 获取 golang.org/toolchain 模块形式的工具链
+	SourceGoProxy = "goproxy"
+	// SourceMirror 从 downloadURL 指向的目录镜像获取，http 地址解析目录索引页，file:// 地址直接读取目录
+	SourceMirror = "mirror"
+	// SourceLocal 从本地归档目录获取
+	SourceLocal = "local"
+)
+
+// SourceNames 支持的版本来源名称
+var SourceNames = []string{SourceGoDev, SourceGoProxy, SourceMirror, SourceLocal}
+
+// Source 版本来源，负责列出版本、定位指定平台的归档以及打开归档
+type Source interface {
+	// ListVersions 返回按版本从新到旧排列的版本列表
+	ListVersions(ctx context.Context, forceUpdate bool) ([]OriginVersion, error)
+	// ResolveArtifact 返回指定版本在 goos/goarch 上的归档
+	ResolveArtifact(ctx context.Context, version, goos, goarch string) (Artifact, error)
+	// OpenArtifact 打开归档内容
+	OpenArtifact(ctx context.Context, artifact Artifact) (io.ReadCloser, error)
+}
+
+// Artifact 某个版本在某个平台上的归档
+type Artifact struct {
+	Filename string
+	// URL 归档地址，http(s) 地址支持分段并发下载
+	URL string
+	// SHA256 和 H1 为期望的归档摘要，都为空时不校验
+	SHA256 string
+	H1     string
+}
+
+// archiveFilename 归档文件名，如 go1.21.3.linux-amd64.tar.gz
+func archiveFilename(version, goos, goarch, suffix string) string {
+	return fmt.Sprintf("%s.%s-%s%s", version, goos, goarch, suffix)
+}
+
+// isStableVersion 判断是否为正式版，无法解析的版本视为非正式版
+func isStableVersion(version string) bool {
+	gv, err := parseGoVersion(version)
+	return err == nil && gv.pre == 2
+}
+
+// originVersionsFromFilenames 根据归档文件名构造版本列表，按版本从新到旧排列，忽略无法识别的文件
+func originVersionsFromFilenames(filenames []string) []OriginVersion {
+	byVersion := make(map[string]*OriginVersion)
+	for _, filename := range filenames {
+		version, goos, goarch, ok := parseArchiveFilename(filename)
+		if !ok {
+			continue
+		}
+		o, exists := byVersion[version]
+		if !exists {
+			o = &OriginVersion{Version: version, Stable: isStableVersion(version)}
+			byVersion[version] = o
+		}
+		o.Files = append(o.Files, File{
+			Filename: filename,
+			OS:       goos,
+			Arch:     goarch,
+			Version:  version,
+			Kind:     "archive",
+		})
+	}
+	originVersions := make([]OriginVersion, 0, len(byVersion))
+	for _, o := range byVersion {
+		originVersions = append(originVersions, *o)
+	}
+	slices.SortFunc(originVersions, func(a, b OriginVersion) int {
+		return CompareVersions(b.Version, a.Version)
+	})
+	return originVersions
+}
+
+// findArtifactFile 在版本列表中查找指定版本和平台的归档，优先使用 tar.gz
+func findArtifactFile(originVersions []OriginVersion, version, goos, goarch string) (File, bool) {
+	for _, suffix := range archiveSuffixes {
+		if file, ok := findOriginFile(originVersions, archiveFilename(version, goos, goarch, suffix)); ok {
+			return file, true
+		}
+	}
+	return File{}, false
+}
+
+// goDevSource go.dev 格式的 JSON 版本列表，归档从 downloadURL 下载，不可用时依次尝试 mirrors
+type goDevSource struct {
+	client      *http.Client
+	originURL   string
+	downloadURL string
+	mirrors     []string
+	cache       originCacheFile
+	logger      log.Logger
+}
+
+func (s *goDevSource) ListVersions(ctx context.Context, forceUpdate bool) ([]OriginVersion, error) {
+	return s.cache.fetch(ctx, s.client, s.originURL, forceUpdate, decodeOriginVersions)
+}
+
+// ResolveArtifact 版本列表中没有对应文件时仍然尝试下载，只是不做校验
+func (s *goDevSource) ResolveArtifact(ctx context.Context, version, goos, goarch string) (Artifact, error) {
+	artifact := Artifact{Filename: archiveFilename(version, goos, goarch, ".tar.gz")}
+	var err error
+	if artifact.URL, err = url.JoinPath(s.downloadURL, artifact.Filename); err != nil {
+		return Artifact{}, fmt.Errorf("failed to join download file url: %v", err)
+	}
+	if originVersions, err := s.ListVersions(ctx, false); err == nil {
+		if file, ok := findOriginFile(originVersions, artifact.Filename); ok {
+			artifact.SHA256 = file.SHA256
+		}
+	}
+	if len(s.mirrors) > 0 {
+		artifact.URL = s.reachableURL(ctx, artifact.URL, artifact.Filename)
+	}
+	return artifact, nil
+}
+
+// reachableURL 依次用 HEAD 请求检查下载地址和各个镜像，返回第一个可用的归档地址，
+// 都不可用时返回下载地址，由下载过程报告错误
+func (s *goDevSource) reachableURL(ctx context.Context, primaryURL, filename string) string {
+	candidates := []string{primaryURL}
+	for _, mirror := range s.mirrors {
+		if u, err := url.JoinPath(mirror, filename); err == nil {
+			candidates = append(candidates, u)
+		}
+	}
+	for _, candidate := range candidates {
+		err := headURL(ctx, s.client, candidate)
+		if err == nil {
+			if candidate != primaryURL {
+				s.logger.Info("using mirror", "mirror", download.RedactURL(candidate))
+			}
+			return candidate
+		}
+		s.logger.Warn("Download URL unavailable, trying the next mirror", "mirror", download.RedactURL(candidate), "error", err)
+	}
+	return primaryURL
+}
+
+func headURL(ctx context.Context, client *http.Client, rawURL string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &download.StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	return nil
+}
+
+func (s *goDevSource) OpenArtifact(ctx context.Context, artifact Artifact) (io.ReadCloser, error) {
+	return download.Open(ctx, s.client, artifact.URL)
+}
+
+// hrefRegex 匹配目录索引页中的链接
+var hrefRegex = regexp.MustCompile(`href="([^"?#]+)"`)
+
+// decodeDirectoryIndex 从 nginx/Apache 等生成的目录索引页中提取归档文件名
+func decodeDirectoryIndex(content []byte) ([]OriginVersion, error) {
+	var filenames []string
+	for _, m := range hrefRegex.FindAllSubmatch(content, -1) {
+		href, err := url.PathUnescape(string(m[1]))
+		if err != nil {
+			continue
+		}
+		filenames = append(filenames, path.Base(href))
+	}
+	return originVersionsFromFilenames(filenames), nil
+}
+
+// mirrorSource 通过 http 访问的目录镜像，版本列表来自目录索引页，摘要来自同名的 .sha256 文件
+type mirrorSource struct {
+	client  *http.Client
+	baseURL string
+	cache   originCacheFile
+}
+
+func (s *mirrorSource) ListVersions(ctx context.Context, forceUpdate bool) ([]OriginVersion, error) {
+	return s.cache.fetch(ctx, s.client, s.baseURL, forceUpdate, decodeDirectoryIndex)
+}
+
+func (s *mirrorSource) ResolveArtifact(ctx context.Context, version, goos, goarch string) (Artifact, error) {
+	originVersions, err := s.ListVersions(ctx, false)
+	if err != nil {
+		return Artifact{}, err
+	}
+	file, ok := findArtifactFile(originVersions, version, goos, goarch)
+	if !ok {
+		return Artifact{}, fmt.Errorf("%w: no archive for %s %s/%s in mirror %s", ErrVersionNotFound, version, goos, goarch, download.RedactURL(s.baseURL))
+	}
+	artifact := Artifact{Filename: file.Filename}
+	if artifact.URL, err = url.JoinPath(s.baseURL, file.Filename); err != nil {
+		return Artifact{}, fmt.Errorf("failed to join download file url: %v", err)
+	}
+	if body, err := download.Open(ctx, s.client, artifact.URL+".sha256"); err == nil {
+		content, _ := io.ReadAll(io.LimitReader(body, 1024))
+		body.Close()
+		artifact.SHA256 = parseChecksumFile(content)
+	}
+	return artifact, nil
+}
+
+func (s *mirrorSource) OpenArtifact(ctx context.Context, artifact Artifact) (io.ReadCloser, error) {
+	return download.Open(ctx, s.client, artifact.URL)
+}
+
+// parseChecksumFile 解析 sha256sum 格式或只包含摘要的 .sha256 文件，格式无效时返回空
+func parseChecksumFile(content []byte) string {
+	fields := strings.Fields(string(content))
+	if len(fields) == 0 || !sha256Regex.MatchString(fields[0]) {
+		return ""
+	}
+	return fields[0]
+}
+
+// localSource 本地归档目录，摘要来自同名的 .sha256 文件
+type localSource struct {
+	dir string
+}
+
+func (s *localSource) ListVersions(context.Context, bool) ([]OriginVersion, error) {
+	dis, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the archive directory: %v", err)
+	}
+	var filenames []string
+	for _, di := range dis {
+		if !di.IsDir() {
+			filenames = append(filenames, di.Name())
+		}
+	}
+	originVersions := originVersionsFromFilenames(filenames)
+	if len(originVersions) == 0 {
+		return nil, fmt.Errorf("no archives found in %s", s.dir)
+	}
+	return originVersions, nil
+}
+
+func (s *localSource) ResolveArtifact(ctx context.Context, version, goos, goarch string) (Artifact, error) {
+	originVersions, err := s.ListVersions(ctx, false)
+	if err != nil {
+		return Artifact{}, err
+	}
+	file, ok := findArtifactFile(originVersions, version, goos, goarch)
+	if !ok {
+		return Artifact{}, fmt.Errorf("%w: no archive for %s %s/%s in %s", ErrVersionNotFound, version, goos, goarch, s.dir)
+	}
+	archivePath := filepath.Join(s.dir, file.Filename)
+	artifact := Artifact{
+		Filename: file.Filename,
+		URL:      (&url.URL{Scheme: "file", Path: filepath.ToSlash(archivePath)}).String(),
+	}
+	if content, err := os.ReadFile(checksumFilePath(archivePath)); err == nil {
+		artifact.SHA256 = parseChecksumFile(content)
+	}
+	return artifact, nil
+}
+
+func (s *localSource) OpenArtifact(_ context.Context, artifact Artifact) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(s.dir, artifact.Filename))
+}
@@ -0,0 +1,305 @@
+package version
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// buildSdkArchive 构造包含安装所需最少文件的 sdk 归档
+func buildSdkArchive(t *testing.T, version string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	files := []struct{ name, body string }{
+		{name: "go/VERSION", body: version},
+		{name: "go/bin/go", body: "#!/bin/sh\n"},
+		{name: "go/pkg/tool/linux_amd64/compile", body: "#!/bin/sh\n"},
+	}
+	for _, f := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Typeflag: tar.TypeReg, Mode: 0755, Size: int64(len(f.body))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(f.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func sha256Hex(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// fakeSource 内存中的版本来源，archives 为版本到当前平台归档内容的映射
+type fakeSource struct {
+	archives map[string][]byte
+	// sha256 覆盖归档的期望摘要
+	sha256 map[string]string
+	opened int
+}
+
+func (s *fakeSource) ListVersions(context.Context, bool) ([]OriginVersion, error) {
+	var filenames []string
+	for version := range s.archives {
+		filenames = append(filenames, archiveFilename(version, runtime.GOOS, runtime.GOARCH, ".tar.gz"))
+	}
+	return originVersionsFromFilenames(filenames), nil
+}
+
+func (s *fakeSource) ResolveArtifact(_ context.Context, version, goos, goarch string) (Artifact, error) {
+	content, ok := s.archives[version]
+	if !ok {
+		return Artifact{}, fmt.Errorf("%w: %s", ErrVersionNotFound, version)
+	}
+	sum := sha256Hex(content)
+	if expected, ok := s.sha256[version]; ok {
+		sum = expected
+	}
+	return Artifact{Filename: archiveFilename(version, goos, goarch, ".tar.gz"), URL: "fake://" + version, SHA256: sum}, nil
+}
+
+func (s *fakeSource) OpenArtifact(_ context.Context, artifact Artifact) (io.ReadCloser, error) {
+	s.opened++
+	version, _, _, _ := parseArchiveFilename(artifact.Filename)
+	return io.NopCloser(bytes.NewReader(s.archives[version])), nil
+}
+
+func newTestVersion(t *testing.T, opts ...VersionOption) *Version {
+	t.Helper()
+	home := t.TempDir()
+	return NewVersion(append([]VersionOption{
+		WithSdkDir(filepath.Join(home, "sdk")),
+		WithCacheDir(filepath.Join(home, "cache")),
+		WithVersionFilePath(filepath.Join(home, "versions.json")),
+		WithLocalVersionFilePath(filepath.Join(home, "version")),
+	}, opts...)...)
+}
+
+func TestNewSourceSelection(t *testing.T) {
+	archiveDir := t.TempDir()
+	fake := &fakeSource{}
+	tests := []struct {
+		name  string
+		opts  []VersionOption
+		check func(t *testing.T, s Source)
+	}{
+		{
+			name: "default",
+			check: func(t *testing.T, s Source) {
+				if _, ok := s.(*goDevSource); !ok {
+					t.Errorf("got %T, want *goDevSource", s)
+				}
+			},
+		},
+		{
+			name: "goproxy",
+			opts: []VersionOption{WithSourceName(SourceGoProxy)},
+			check: func(t *testing.T, s Source) {
+				if _, ok := s.(*goProxySource); !ok {
+					t.Errorf("got %T, want *goProxySource", s)
+				}
+			},
+		},
+		{
+			name: "http mirror",
+			opts: []VersionOption{WithSourceName(SourceMirror), WithDownloadURL("https://mirror.example/go/")},
+			check: func(t *testing.T, s Source) {
+				if m, ok := s.(*mirrorSource); !ok || m.baseURL != "https://mirror.example/go/" {
+					t.Errorf("got %#v, want *mirrorSource", s)
+				}
+			},
+		},
+		{
+			name: "file mirror",
+			opts: []VersionOption{WithSourceName(SourceMirror), WithDownloadURL("file://" + filepath.ToSlash(archiveDir))},
+			check: func(t *testing.T, s Source) {
+				if l, ok := s.(*localSource); !ok || l.dir != archiveDir {
+					t.Errorf("got %#v, want *localSource for %s", s, archiveDir)
+				}
+			},
+		},
+		{
+			name: "local",
+			opts: []VersionOption{WithSourceName(SourceLocal), WithArchiveDir(archiveDir)},
+			check: func(t *testing.T, s Source) {
+				if l, ok := s.(*localSource); !ok || l.dir != archiveDir {
+					t.Errorf("got %#v, want *localSource for %s", s, archiveDir)
+				}
+			},
+		},
+		{
+			name: "explicit source",
+			opts: []VersionOption{WithSourceName(SourceGoProxy), WithSource(fake)},
+			check: func(t *testing.T, s Source) {
+				if s != fake {
+					t.Errorf("got %T, want the source passed to WithSource", s)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.check(t, newTestVersion(t, tt.opts...).source)
+		})
+	}
+}
+
+func TestInstallFromSource(t *testing.T) {
+	fake := &fakeSource{archives: map[string][]byte{
+		"go1.22.0": buildSdkArchive(t, "go1.22.0"),
+		"go1.22.1": buildSdkArchive(t, "go1.22.1"),
+	}}
+	v := newTestVersion(t, WithSource(fake))
+
+	version, err := v.ResolveVersion(context.Background(), "1.22", false)
+	if err != nil || version != "go1.22.1" {
+		t.Fatalf("ResolveVersion = %s, %v, want go1.22.1", version, err)
+	}
+	installed, err := v.InstallVersion(context.Background(), version, false, func(string, ...any) {})
+	if err != nil || !installed {
+		t.Fatalf("InstallVersion = %v, %v", installed, err)
+	}
+	content, err := os.ReadFile(filepath.Join(v.SdkFilePath(version), "VERSION"))
+	if err != nil || string(content) != "go1.22.1" {
+		t.Fatalf("VERSION = %q, %v", content, err)
+	}
+	installed, err = v.InstallVersion(context.Background(), version, false, func(string, ...any) {})
+	if err != nil || installed || fake.opened != 1 {
+		t.Errorf("second install = %v, %v, opened %d times, want it skipped", installed, err, fake.opened)
+	}
+}
+
+func TestInstallWritesChecksumFile(t *testing.T) {
+	for _, connections := range []int{1, 2} {
+		t.Run(fmt.Sprintf("connections %d", connections), func(t *testing.T) {
+			content := buildSdkArchive(t, "go1.22.1")
+			v := newTestVersion(t, WithSource(&fakeSource{archives: map[string][]byte{"go1.22.1": content}}), WithConnections(connections))
+			if _, err := v.InstallVersion(context.Background(), "go1.22.1", false, func(string, ...any) {}); err != nil {
+				t.Fatal(err)
+			}
+			archives, err := v.listCacheArchives()
+			if err != nil || len(archives) != 1 {
+				t.Fatalf("cache archives = %+v, %v", archives, err)
+			}
+			if !archives[0].isVerified() {
+				t.Error("installed archive is not marked as verified")
+			}
+			sum, err := os.ReadFile(checksumFilePath(archives[0].Path))
+			if err != nil || string(sum) != sha256Hex(content)+"\n" {
+				t.Errorf("checksum file = %q, %v", sum, err)
+			}
+		})
+	}
+}
+
+func TestInstallFromSourceChecksumMismatch(t *testing.T) {
+	fake := &fakeSource{
+		archives: map[string][]byte{"go1.22.1": buildSdkArchive(t, "go1.22.1")},
+		sha256:   map[string]string{"go1.22.1": sha256Hex([]byte("other"))},
+	}
+	v := newTestVersion(t, WithSource(fake))
+	_, err := v.InstallVersion(context.Background(), "go1.22.1", false, func(string, ...any) {})
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("got %v, want ErrChecksumMismatch", err)
+	}
+	if _, err := os.Stat(v.SdkFilePath("go1.22.1")); !os.IsNotExist(err) {
+		t.Errorf("sdk directory left behind: %v", err)
+	}
+}
+
+func TestMirrorSourceResolveArtifact(t *testing.T) {
+	archive := buildSdkArchive(t, "go1.22.1")
+	filename := archiveFilename("go1.22.1", "linux", "amd64", ".tar.gz")
+	mux := http.NewServeMux()
+	mux.HandleFunc("/go/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<a href="../">../</a><a href="%s">%s</a><a href="%s.sha256">%s.sha256</a>`, filename, filename, filename, filename)
+	})
+	mux.HandleFunc("/go/"+filename+".sha256", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s  %s\n", sha256Hex(archive), filename)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	v := newTestVersion(t, WithSourceName(SourceMirror), WithDownloadURL(srv.URL+"/go/"))
+	artifact, err := v.source.ResolveArtifact(context.Background(), "go1.22.1", "linux", "amd64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if artifact.URL != srv.URL+"/go/"+filename || artifact.SHA256 != sha256Hex(archive) {
+		t.Errorf("got %+v", artifact)
+	}
+	if _, err := v.source.ResolveArtifact(context.Background(), "go1.22.1", "windows", "amd64"); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("got %v, want ErrVersionNotFound", err)
+	}
+}
+
+func TestLocalSourceResolveArtifact(t *testing.T) {
+	archiveDir := t.TempDir()
+	archive := buildSdkArchive(t, "go1.21.0")
+	filename := archiveFilename("go1.21.0", "linux", "arm64", ".tar.gz")
+	if err := os.WriteFile(filepath.Join(archiveDir, filename), archive, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(archiveDir, filename+".sha256"), []byte(sha256Hex(archive)+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	v := newTestVersion(t, WithSourceName(SourceLocal), WithArchiveDir(archiveDir))
+	versions, err := v.OriginVersions(context.Background(), false)
+	if err != nil || len(versions) != 1 || versions[0].Version != "go1.21.0" {
+		t.Fatalf("OriginVersions = %+v, %v", versions, err)
+	}
+	artifact, err := v.source.ResolveArtifact(context.Background(), "go1.21.0", "linux", "arm64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if artifact.SHA256 != sha256Hex(archive) {
+		t.Errorf("SHA256 = %q, want the .sha256 file content", artifact.SHA256)
+	}
+}
+
+func TestGoDevSourceMirrorFallback(t *testing.T) {
+	primary := httptest.NewServer(http.NotFoundHandler())
+	defer primary.Close()
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer mirror.Close()
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"version":"go1.22.1","stable":true,"files":[{"filename":"go1.22.1.linux-amd64.tar.gz","os":"linux","arch":"amd64","version":"go1.22.1","sha256":"`+sha256Hex(nil)+`","kind":"archive"}]}]`)
+	}))
+	defer origin.Close()
+
+	v := newTestVersion(t, WithOriginURL(origin.URL), WithDownloadURL(primary.URL+"/dl/"), WithMirrors([]string{mirror.URL + "/go/"}))
+	artifact, err := v.source.ResolveArtifact(context.Background(), "go1.22.1", "linux", "amd64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if artifact.URL != mirror.URL+"/go/go1.22.1.linux-amd64.tar.gz" {
+		t.Errorf("URL = %s, want the mirror", artifact.URL)
+	}
+	if artifact.SHA256 != sha256Hex(nil) {
+		t.Errorf("SHA256 = %q, want the checksum from the origin", artifact.SHA256)
+	}
+}
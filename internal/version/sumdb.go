@@ -0,0 +1,109 @@
+package version
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/mod/sumdb"
+
+	"github.com/aide-cloud/gvm/pkg/dir"
+	"github.com/aide-cloud/gvm/pkg/download"
+	"github.com/aide-cloud/gvm/pkg/log"
+)
+
+// sumDBOps 实现 sumdb.ClientOps。远程内容先从校验和数据库读取，失败时按 GOPROXY 的回退规则通过代理读取；
+// 数据库的签名树和 tile 保存在 dir 下，下次查询时用来确认数据库没有改写已签名的历史
+type sumDBOps struct {
+	ctx     context.Context
+	client  *http.Client
+	db      sumDB
+	proxies []goProxyEntry
+	dir     string
+	logger  log.Logger
+}
+
+func (o *sumDBOps) ReadRemote(path string) ([]byte, error) {
+	content, err := o.get(o.db.url + path)
+	if err == nil {
+		return content, nil
+	}
+	if o.ctx.Err() != nil {
+		return nil, o.ctx.Err()
+	}
+	proxyErr := tryProxies(o.ctx, o.proxies, func(proxy string) error {
+		var err error
+		content, err = o.get(proxy + "/sumdb/" + o.db.name + path)
+		return err
+	})
+	if proxyErr != nil {
+		return nil, fmt.Errorf("%s: %w; %w", o.db.url, err, proxyErr)
+	}
+	return content, nil
+}
+
+func (o *sumDBOps) get(rawURL string) ([]byte, error) {
+	body, err := download.Open(o.ctx, o.client, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
+
+// ReadConfig key 为 GOSUMDB 中的公钥，<name>/latest 为上次验证过的签名树，不存在时从空树开始
+func (o *sumDBOps) ReadConfig(file string) ([]byte, error) {
+	if file == "key" {
+		return []byte(o.db.key), nil
+	}
+	content, err := os.ReadFile(o.path(file))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return content, err
+}
+
+func (o *sumDBOps) WriteConfig(file string, old, new []byte) error {
+	current, err := o.ReadConfig(file)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(current, old) {
+		return sumdb.ErrWriteConflict
+	}
+	return o.write(file, new)
+}
+
+func (o *sumDBOps) ReadCache(file string) ([]byte, error) {
+	return os.ReadFile(o.path(file))
+}
+
+// WriteCache 缓存写入失败只影响下次查询的速度
+func (o *sumDBOps) WriteCache(file string, data []byte) {
+	_ = o.write(file, data)
+}
+
+func (o *sumDBOps) Log(msg string) {
+	o.logger.Debug(msg)
+}
+
+func (o *sumDBOps) SecurityError(msg string) {
+	o.logger.Error(msg)
+}
+
+func (o *sumDBOps) path(file string) string {
+	return filepath.Join(o.dir, filepath.FromSlash(file))
+}
+
+// write 先写入临时文件再重命名，避免并发的 gvm 进程读到写了一半的文件
+func (o *sumDBOps) write(file string, data []byte) error {
+	path := o.path(file)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return dir.WriteFileAtomic(path, data, 0644)
+}
@@ -0,0 +1,110 @@
+package version
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveInstalledVersion(t *testing.T) {
+	installed := []string{"go1.2.2", "go1.21.5", "go1.22", "go1.22.1", "go1.22rc1"}
+	aliases := map[string]Alias{
+		"stable": {Target: "1.21"},
+		"pinned": {Target: "go1.22", Frozen: true},
+	}
+	tests := []struct {
+		target  string
+		want    string
+		wantErr error
+	}{
+		{target: "go1.22", want: "go1.22"},
+		{target: "1.22.1", want: "go1.22.1"},
+		{target: "1.22", want: "go1.22.1"},
+		{target: "1.2", want: "go1.2.2"},
+		{target: "latest", want: "go1.22.1"},
+		{target: "stable", want: "go1.21.5"},
+		{target: "pinned", want: "go1.22"},
+		{target: "1.23", wantErr: ErrNotInstalled},
+		{target: "1.21.4", wantErr: ErrNotInstalled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			got, err := resolveInstalledVersion(installed, aliases, tt.target)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %s, %v, want %v", got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("got %s, %v, want %s", got, err, tt.want)
+			}
+		})
+	}
+}
+
+// newInstalledVersion 返回安装并切换到 version 的 gvm，shell 配置写在临时的 HOME 下
+func newInstalledVersion(t *testing.T, version string) (*Version, string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	bashrc := filepath.Join(home, ".bashrc")
+	if err := os.WriteFile(bashrc, []byte("alias ll='ls -l'\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeSource{archives: map[string][]byte{version: buildSdkArchive(t, version)}}
+	v := newTestVersion(t, WithSource(fake), WithShell("bash"))
+	if _, err := v.InstallVersion(context.Background(), version, false, func(string, ...any) {}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := v.ActivateVersion(context.Background(), version); err != nil {
+		t.Fatal(err)
+	}
+	return v, bashrc
+}
+
+func TestUninstallActiveVersionClearsGOROOT(t *testing.T) {
+	v, bashrc := newInstalledVersion(t, "go1.22.1")
+	if err := v.Uninstall(context.Background(), []string{"1.22"}, true, true); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(bashrc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "GOROOT") {
+		t.Errorf("GOROOT still points at the removed version:\n%s", content)
+	}
+	if !strings.Contains(string(content), "alias ll=") {
+		t.Errorf("other settings were removed:\n%s", content)
+	}
+	if current := v.CurrentVersion(); current != "" {
+		t.Errorf("current version = %q, want it cleared", current)
+	}
+}
+
+func TestUninstallRequiresConfirmationWithoutTerminal(t *testing.T) {
+	v, _ := newInstalledVersion(t, "go1.22.1")
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdin := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		r.Close()
+	})
+	_, _ = w.WriteString("n\n")
+	w.Close()
+
+	if err := v.Uninstall(context.Background(), []string{"go1.22.1"}, true, false); err == nil {
+		t.Fatal("expected an error when the uninstall is not confirmed")
+	}
+	if _, err := os.Stat(v.SdkFilePath("go1.22.1")); err != nil {
+		t.Errorf("version was removed without confirmation: %v", err)
+	}
+}
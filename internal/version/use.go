@@ -1,46 +1,34 @@
 package version
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
 
 	"github.com/aide-cloud/gvm/pkg/dir"
-	"github.com/aide-cloud/gvm/pkg/log"
 )
 
-func Use(version, sdkDir, localVersionFilePath string, isEval bool) error {
+// Use 在 shell 配置文件中设置 GOROOT 并写入本地版本文件，返回修改的 shell 配置文件路径。
+// shell 为 bash、zsh 或其路径，为空时按环境变量 SHELL 判断
+func Use(version, sdkDir, localVersionFilePath, shell string) (string, error) {
 	sdkDir = dir.ExpandHomeDir(sdkDir)
 	sdkFilePath := filepath.Join(sdkDir, version)
 	exist, err := dir.CheckFileExists(sdkFilePath)
 	if err != nil {
-		return err
+		return "", err
 	}
 	if !exist {
-		return fmt.Errorf("version %s not found", version)
+		return "", fmt.Errorf("%w: %s", ErrNotInstalled, version)
 	}
-	// ~/.zshrc set the go root
-	shell := os.Getenv("SHELL")
-	var (
-		shellConfig     []byte
-		shellConfigPath string
-	)
-	switch shell {
-	case "/bin/zsh", "/bin/sh":
-		shellConfigPath = filepath.Join(os.Getenv("HOME"), ".zshrc")
-		shellConfig, err = os.ReadFile(shellConfigPath)
-		if err != nil {
-			return err
-		}
-	case "/bin/bash":
-		shellConfigPath = filepath.Join(os.Getenv("HOME"), ".bashrc")
-		shellConfig, err = os.ReadFile(shellConfigPath)
-		if err != nil {
-			return err
-		}
-	default:
-		return fmt.Errorf("unsupported shell: %s", shell)
+	shellConfigPath, err := shellConfigFile(shell)
+	if err != nil {
+		return "", err
+	}
+	shellConfig, err := os.ReadFile(shellConfigPath)
+	if err != nil {
+		return "", err
 	}
 
 	exportGOROOT := fmt.Sprintf(`export GOROOT="%s"`, sdkFilePath)
@@ -52,19 +40,70 @@ func Use(version, sdkDir, localVersionFilePath string, isEval bool) error {
 		shellConfig = append(shellConfig, []byte("\n"+exportGOROOT)...)
 	}
 	if err := os.WriteFile(shellConfigPath, []byte(shellConfig), 0644); err != nil {
-		return err
-	}
-	log.Info("set", "GOROOT", sdkFilePath)
-	if isEval {
-		fmt.Printf("source %s\n", shellConfigPath)
-	} else {
-		fmt.Printf("execute command:\n\t eval \"source %s\"\n", shellConfigPath)
+		return "", err
 	}
 
 	// 写入版本文件
 	if err := os.WriteFile(localVersionFilePath, []byte(version), 0644); err != nil {
-		log.Error("Failed to write version file:", "error", err)
+		return "", fmt.Errorf("failed to write version file: %w", err)
+	}
+	return shellConfigPath, nil
+}
+
+// writeLocalVersion 确认版本已安装并写入本地版本文件，不修改 shell 配置
+func writeLocalVersion(version, sdkDir, localVersionFilePath string) error {
+	exist, err := dir.CheckFileExists(filepath.Join(dir.ExpandHomeDir(sdkDir), version))
+	if err != nil {
 		return err
 	}
+	if !exist {
+		return fmt.Errorf("%w: %s", ErrNotInstalled, version)
+	}
+	if err := os.WriteFile(localVersionFilePath, []byte(version), 0644); err != nil {
+		return fmt.Errorf("failed to write version file: %w", err)
+	}
 	return nil
 }
+
+// shellConfigFile 返回 shell 对应的配置文件，shell 为空时按环境变量 SHELL 判断
+func shellConfigFile(shell string) (string, error) {
+	// ~/.zshrc set the go root
+	if shell == "" {
+		shell = os.Getenv("SHELL")
+	}
+	switch filepath.Base(shell) {
+	case "zsh", "sh":
+		return filepath.Join(os.Getenv("HOME"), ".zshrc"), nil
+	case "bash":
+		return filepath.Join(os.Getenv("HOME"), ".bashrc"), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedShell, shell)
+	}
+}
+
+// clearGOROOT 从 shell 配置文件中删除 gvm use 写入的指向 sdkFilePath 的 GOROOT 设置，
+// 返回修改的配置文件路径，没有需要删除的设置时返回空
+func clearGOROOT(sdkFilePath, shell string) (string, error) {
+	shellConfigPath, err := shellConfigFile(shell)
+	if errors.Is(err, ErrUnsupportedShell) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	shellConfig, err := os.ReadFile(shellConfigPath)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	exportRegex := regexp.MustCompile(`(?m)^export GOROOT="` + regexp.QuoteMeta(sdkFilePath) + `"\n?`)
+	if !exportRegex.Match(shellConfig) {
+		return "", nil
+	}
+	if err := os.WriteFile(shellConfigPath, exportRegex.ReplaceAll(shellConfig, nil), 0644); err != nil {
+		return "", err
+	}
+	return shellConfigPath, nil
+}
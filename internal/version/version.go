@@ -1,16 +1,27 @@
 package version
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
 	"slices"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
 
 	"github.com/aide-cloud/gvm/pkg/dir"
+	"github.com/aide-cloud/gvm/pkg/lock"
 	"github.com/aide-cloud/gvm/pkg/log"
+	"github.com/aide-cloud/gvm/pkg/output"
+	"github.com/aide-cloud/gvm/pkg/prompt"
 )
 
 const (
@@ -20,6 +31,8 @@ const (
 	DefaultDownloadURL          = "https://dl.google.com/go/"
 	DefaultVersionFilePath      = "~/.gvm/versions.json"
 	DefaultLocalVersionFilePath = "~/.gvm/version"
+	DefaultOriginTTL            = 24 * time.Hour
+	DefaultLockTimeout          = 5 * time.Minute
 )
 
 type Version struct {
@@ -29,6 +42,20 @@ type Version struct {
 	localVersionFilePath string
 	originURL            string
 	downloadURL          string
+	mirrors              []string
+	originTTL            time.Duration
+	lockTimeout          time.Duration
+	noCache              bool
+	connections          int
+	httpClient           *http.Client
+	sourceName           string
+	source               Source
+	goProxy              GoProxyConfig
+	archiveDir           string
+	shell                string
+	noShellConfig        bool
+	output               output.Format
+	logger               log.Logger
 }
 
 type VersionOption func(*Version)
@@ -41,183 +68,663 @@ func NewVersion(opts ...VersionOption) *Version {
 		localVersionFilePath: dir.ExpandHomeDir(DefaultLocalVersionFilePath),
 		originURL:            DefaultOriginURL,
 		downloadURL:          DefaultDownloadURL,
+		originTTL:            DefaultOriginTTL,
+		lockTimeout:          DefaultLockTimeout,
+		httpClient:           http.DefaultClient,
+		sourceName:           SourceGoDev,
+		output:               output.Table,
+		logger:               log.Default(),
 	}
 	for _, opt := range opts {
 		opt(v)
 	}
+	if v.source == nil {
+		v.source = v.newSource()
+	}
 	// 检查目录是否存在，如果不存在，则创建
 	if _, err := os.Stat(v.sdkDir); os.IsNotExist(err) {
 		if err := os.MkdirAll(v.sdkDir, 0755); err != nil {
-			log.Error("Failed to create sdk directory:", "error", err)
+			v.logger.Error("Failed to create sdk directory:", "error", err)
 		}
 	}
 	if _, err := os.Stat(v.cacheDir); os.IsNotExist(err) {
 		if err := os.MkdirAll(v.cacheDir, 0755); err != nil {
-			log.Error("Failed to create cache directory:", "error", err)
+			v.logger.Error("Failed to create cache directory:", "error", err)
 		}
 	}
 	versionFileDir := filepath.Dir(v.versionFilePath)
 	if _, err := os.Stat(versionFileDir); os.IsNotExist(err) {
 		if err := os.MkdirAll(versionFileDir, 0755); err != nil {
-			log.Error("Failed to create version file directory:", "error", err)
+			v.logger.Error("Failed to create version file directory:", "error", err)
 		}
 	}
 	if _, err := os.Stat(v.localVersionFilePath); os.IsNotExist(err) {
 		if err := os.WriteFile(v.localVersionFilePath, []byte(""), 0644); err != nil {
-			log.Error("Failed to create local version file:", "error", err)
+			v.logger.Error("Failed to create local version file:", "error", err)
 		}
 	}
 	// 检查sdk目前权限，如果权限不正确，则设置为755
 	if err := setPermissionsRecursively(v.sdkDir, 0755); err != nil {
-		log.Error("Failed to set permissions to sdk directory:", "error", err)
+		v.logger.Error("Failed to set permissions to sdk directory:", "error", err)
 	}
 	return v
 }
 
-func (v *Version) Use(targetVersion string, isForce, isEval bool) {
-	version, err := v.getOriginVersion(targetVersion, false)
+func (v *Version) Use(ctx context.Context, targetVersion string, isForce, isEval bool) error {
+	var (
+		version string
+		err     error
+	)
+	if targetVersion == "-" {
+		version, err = v.previousVersion()
+	} else {
+		version, err = v.ResolveVersion(ctx, targetVersion, false)
+	}
 	if err != nil {
-		log.Error("Failed to get version:", "error", err)
-		return
+		return err
 	}
 	exist, err := v.checkLocalVersion(version)
 	if err != nil {
-		log.Error("Failed to check local version:", "error", err)
-		return
+		return err
 	}
 
-	tarGzFilename := v.tarGzFilename(version)
-	cacheFilePath := v.cacheFilePath(tarGzFilename)
-	sdkFilePath := v.sdkFilePath(version)
-	downloadFileUrl, err := url.JoinPath(v.downloadURL, tarGzFilename)
+	installed := false
+	if !exist || isForce {
+		installResult, err := v.installTarget(ctx, targetVersion, version, isForce, v.stepProgress(version))
+		if err != nil {
+			return fmt.Errorf("failed to install %s: %w", version, err)
+		}
+		installed = installResult.Status == StatusInstalled
+	}
+	result, err := v.ActivateVersion(ctx, version)
 	if err != nil {
-		log.Error("Failed to join download file url", "error", err)
-		return
+		return err
+	}
+	result.Installed = installed
+	if v.printResult(result) {
+		return nil
+	}
+	if isEval {
+		fmt.Printf("source %s\n", result.ShellConfig)
+	} else {
+		fmt.Printf("execute command:\n\t eval \"source %s\"\n", result.ShellConfig)
 	}
+	return nil
+}
 
-	if !exist || isForce {
-		if isForce && exist {
-			_ = os.RemoveAll(cacheFilePath)
-			_ = os.RemoveAll(sdkFilePath)
+func (v *Version) Install(ctx context.Context, targetVersion string, isForce bool) error {
+	version, err := v.ResolveVersion(ctx, targetVersion, false)
+	if err != nil {
+		v.printResult([]InstallResult{{Target: targetVersion, Status: StatusFailed, Error: err.Error()}})
+		return err
+	}
+	result, err := v.installTarget(ctx, targetVersion, version, isForce, v.stepProgress(version))
+	v.printResult([]InstallResult{result})
+	if err != nil {
+		return fmt.Errorf("failed to install %s: %w", version, err)
+	}
+	return nil
+}
+
+// ActivateVersion 将已安装的版本设为当前版本：在 shell 配置中设置 GOROOT，写入本地版本文件并记录切换历史
+func (v *Version) ActivateVersion(ctx context.Context, version string) (UseResult, error) {
+	result := UseResult{Version: version, GOROOT: v.SdkFilePath(version)}
+	stateLock, err := v.lockState(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer stateLock.Release()
+	result.Previous = v.CurrentVersion()
+	if v.noShellConfig {
+		err = writeLocalVersion(version, v.sdkDir, v.localVersionFilePath)
+	} else {
+		result.ShellConfig, err = Use(version, v.sdkDir, v.localVersionFilePath, v.shell)
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to use %s: %w", version, err)
+	}
+	v.logger.Info("set", "GOROOT", result.GOROOT)
+	workDir, _ := os.Getwd()
+	entry := HistoryEntry{Version: version, Previous: result.Previous, Time: time.Now(), Dir: workDir}
+	if err := AppendHistory(v.historyFilePath(), entry); err != nil {
+		v.logger.Error("Failed to record history:", "error", err)
+	}
+	return result, nil
+}
+
+// installTarget 安装已解析的版本并记录结果，安装完成后输出一条带版本和耗时的日志
+func (v *Version) installTarget(ctx context.Context, target, version string, isForce bool, progress ProgressFunc) (InstallResult, error) {
+	result := InstallResult{Target: target, Version: version, Path: v.SdkFilePath(version), Status: StatusInstalled}
+	logger := log.With(v.logger, "version", version)
+	start := time.Now()
+	installed, err := v.InstallVersion(ctx, version, isForce, progress)
+	duration := time.Since(start)
+	result.DurationMs = duration.Milliseconds()
+	switch {
+	case err != nil:
+		result.Status = StatusFailed
+		result.Error = err.Error()
+	case !installed:
+		result.Status = StatusAlreadyInstalled
+		logger.Info("version already installed", "path", result.Path)
+	default:
+		logger.Info("installed version", "path", result.Path, "duration", duration.Round(time.Millisecond))
+	}
+	return result, err
+}
+
+// stepProgress 以 Debug 级别输出安装的每个步骤，--verbose 时可见
+func (v *Version) stepProgress(version string) ProgressFunc {
+	return log.With(v.logger, "version", version).Debug
+}
+
+// InstallAll 使用最多 jobs 个并发任务安装多个版本，逐个版本输出进度并在最后输出汇总，
+// 任意版本安装失败时返回错误，错误类型取第一个失败的版本
+func (v *Version) InstallAll(ctx context.Context, targetVersions []string, isForce bool, jobs int) error {
+	if len(targetVersions) == 0 {
+		return fmt.Errorf("no versions to install")
+	}
+	jobs = max(1, min(jobs, len(targetVersions)))
+
+	// 先依次解析版本，避免并发任务同时刷新版本缓存
+	results := make([]InstallResult, len(targetVersions))
+	errs := make([]error, len(targetVersions))
+	for i, target := range targetVersions {
+		results[i] = InstallResult{Target: target}
+		version, err := v.ResolveVersion(ctx, target, false)
+		if err != nil {
+			results[i].Status = StatusFailed
+			results[i].Error = err.Error()
+			errs[i] = err
+			continue
 		}
-		if err := Install(cacheFilePath, sdkFilePath, downloadFileUrl); err != nil {
-			log.Error("Failed to install version:", "error", err)
-			return
+		results[i].Version = version
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				version := results[i].Version
+				results[i], errs[i] = v.installTarget(ctx, results[i].Target, version, isForce, v.versionProgress(version))
+			}
+		}()
+	}
+	for i := range results {
+		if results[i].Status != StatusFailed {
+			indexes <- i
 		}
 	}
-	if err := Use(version, v.sdkDir, v.localVersionFilePath, isEval); err != nil {
-		log.Error("Failed to use version:", "error", err)
-		return
+	close(indexes)
+	wg.Wait()
+
+	failed := 0
+	var firstErr error
+	out := v.textOut()
+	fmt.Fprintln(out, "Summary:")
+	for i, r := range results {
+		name := r.Target
+		if r.Version != "" {
+			name = r.Version
+		}
+		if r.Status == StatusFailed {
+			failed++
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+			fmt.Fprintf(out, "  %-12s failed     %s\n", name, r.Error)
+			continue
+		}
+		fmt.Fprintf(out, "  %-12s %-10s %s\n", name, r.Status, (time.Duration(r.DurationMs) * time.Millisecond).String())
+	}
+	v.printResult(results)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d versions failed to install: %w", failed, len(results), firstErr)
+	}
+	return nil
+}
+
+// versionProgress 返回带版本前缀输出进度的回调，用于并发安装时区分各个版本
+func (v *Version) versionProgress(version string) ProgressFunc {
+	out := v.textOut()
+	return func(stage string, args ...any) {
+		fmt.Fprintf(out, "[%s] %s\n", version, stage)
 	}
 }
 
-func (v *Version) Install(targetVersion string, isForce bool) {
-	version, err := v.getOriginVersion(targetVersion, false)
+// InstallVersion 持有版本锁安装指定版本，已安装且未强制安装时跳过，返回是否进行了安装
+func (v *Version) InstallVersion(ctx context.Context, version string, isForce bool, progress ProgressFunc) (bool, error) {
+	versionLock, err := v.lockVersion(ctx, version)
 	if err != nil {
-		log.Error("Failed to get version:", "error", err)
-		return
+		return false, err
 	}
+	defer versionLock.Release()
+
+	// 在锁内重新检查，其他进程可能已经完成了安装
 	exist, err := v.checkLocalVersion(version)
 	if err != nil {
-		log.Error("Failed to check local version:", "error", err)
-		return
+		return false, fmt.Errorf("failed to check local version: %v", err)
+	}
+	if exist && !isForce {
+		progress("Version already installed", "version", version)
+		return false, nil
 	}
-	tarGzFilename := v.tarGzFilename(version)
-	cacheFilePath := v.cacheFilePath(tarGzFilename)
-	sdkFilePath := v.sdkFilePath(version)
-	downloadFileUrl, err := url.JoinPath(v.downloadURL, tarGzFilename)
+
+	task, err := v.installTask(ctx, version)
 	if err != nil {
-		log.Error("Failed to join download file url", "error", err)
-		return
+		return false, err
+	}
+	if exist {
+		_ = os.RemoveAll(task.CacheFilePath)
+		_ = os.RemoveAll(task.SdkFilePath)
+	}
+	if err := Install(ctx, task, progress); err != nil {
+		return false, err
 	}
+	return true, nil
+}
 
-	if exist && !isForce {
-		log.Info("Version already installed", "version", version)
-		return
+// installTask 从版本来源解析当前平台的归档并构造安装参数
+func (v *Version) installTask(ctx context.Context, version string) (InstallTask, error) {
+	artifact, err := v.source.ResolveArtifact(ctx, version, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return InstallTask{}, err
+	}
+	task := InstallTask{
+		Client:          v.httpClient,
+		CacheFilePath:   v.cacheFilePath(artifact.Filename),
+		SdkFilePath:     v.SdkFilePath(version),
+		DownloadFileURL: artifact.URL,
+		Open: func(ctx context.Context) (io.ReadCloser, error) {
+			return v.source.OpenArtifact(ctx, artifact)
+		},
+		SHA256:      artifact.SHA256,
+		H1:          artifact.H1,
+		NoCache:     v.noCache,
+		Connections: v.connections,
+	}
+	if task.SHA256 == "" && task.H1 == "" {
+		v.logger.Warn("No checksum found in origin metadata, skipping verification", "version", version)
+	}
+	return task, nil
+}
+
+func (v *Version) Uninstall(ctx context.Context, targetVersions []string, isForce, isYes bool) error {
+	versions, err := v.resolveInstalledVersions(targetVersions)
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		v.logger.Info("No installed versions matched")
+		v.printResult([]UninstallResult{})
+		return nil
+	}
+	localVersion := v.CurrentVersion()
+	if slices.Contains(versions, localVersion) && !isForce {
+		return fmt.Errorf("%w %s, use --force to uninstall it anyway", ErrActiveVersion, localVersion)
 	}
 
-	if isForce && exist {
-		_ = os.RemoveAll(cacheFilePath)
-		_ = os.RemoveAll(sdkFilePath)
+	out := v.textOut()
+	fmt.Fprintln(out, "The following versions will be uninstalled:")
+	results := make([]UninstallResult, 0, len(versions))
+	var total int64
+	for _, version := range versions {
+		sdkFilePath := v.SdkFilePath(version)
+		size, _ := dir.Size(sdkFilePath)
+		total += size
+		results = append(results, UninstallResult{Version: version, Path: sdkFilePath, Size: size})
+		fmt.Fprintf(out, "  %-12s %10s  %s\n", version, dir.FormatSize(size), sdkFilePath)
+	}
+	fmt.Fprintf(out, "Total: %s\n", dir.FormatSize(total))
+	if !isYes && !prompt.Confirm(ctx, fmt.Sprintf("Uninstall %d version(s)?", len(versions))) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// 脚本中没有确认时不能当作成功
+		if !prompt.IsTerminal() {
+			return fmt.Errorf("uninstall not confirmed and stdin is not a terminal, use --yes to uninstall without confirmation")
+		}
+		v.logger.Info("Uninstall cancelled")
+		return nil
 	}
 
-	if err := Install(cacheFilePath, sdkFilePath, downloadFileUrl); err != nil {
-		log.Error("Failed to install version:", "error", err)
-		return
+	for i, version := range versions {
+		if err := v.UninstallVersion(ctx, version); err != nil {
+			results[i].Status = StatusFailed
+			results[i].Error = err.Error()
+			v.printResult(results[:i+1])
+			return fmt.Errorf("failed to uninstall %s: %w", version, err)
+		}
+		results[i].Status = StatusUninstalled
+		if version == localVersion {
+			shellConfigPath, err := v.resetCurrentVersion(ctx, version)
+			if err != nil {
+				v.logger.Error("Failed to reset the active version:", "error", err)
+			}
+			v.logger.Warn("Uninstalled the active version, run gvm use to select another one", "version", version, "shellConfig", shellConfigPath)
+		}
 	}
+	v.printResult(results)
+	return nil
 }
 
-func (v *Version) Uninstall(targetVersion string) {
-	version, err := v.getOriginVersion(targetVersion, false)
+// UninstallVersion 持有版本锁卸载指定版本
+func (v *Version) UninstallVersion(ctx context.Context, version string) error {
+	versionLock, err := v.lockVersion(ctx, version)
 	if err != nil {
-		log.Error("Failed to get version:", "error", err)
-		return
+		return err
 	}
-	exist, err := v.checkLocalVersion(version)
+	defer versionLock.Release()
+	sdkFilePath := v.SdkFilePath(version)
+	v.logger.Info("uninstalling version", "version", version, "sdkFilePath", sdkFilePath)
+	if err := Uninstall(version, sdkFilePath); err != nil {
+		return err
+	}
+	v.logger.Info("uninstalled version", "version", version, "sdkFilePath", sdkFilePath)
+	return nil
+}
+
+// resetCurrentVersion 当前版本已被删除时清空本地版本文件，并从 shell 配置中删除指向它的 GOROOT，
+// 避免新的 shell 使用不存在的目录，返回修改的 shell 配置文件路径
+func (v *Version) resetCurrentVersion(ctx context.Context, version string) (string, error) {
+	stateLock, err := v.lockState(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer stateLock.Release()
+	if err := os.WriteFile(v.localVersionFilePath, []byte(""), 0644); err != nil {
+		return "", err
+	}
+	if v.noShellConfig {
+		return "", nil
+	}
+	return clearGOROOT(v.SdkFilePath(version), v.shell)
+}
+
+// resolveInstalledVersions 将版本参数解析为已安装的版本列表，只在已安装的版本中查找，不访问版本来源；
+// 约束表达式（如 '<1.21'）匹配所有满足条件的已安装版本
+func (v *Version) resolveInstalledVersions(targetVersions []string) ([]string, error) {
+	installed, err := FetchLocalVersions(v.sdkDir)
 	if err != nil {
-		log.Error("Failed to check local version:", "error", err)
-		return
+		return nil, fmt.Errorf("failed to fetch local versions: %v", err)
 	}
-	if !exist {
-		log.Error("Version not installed", "version", version)
-		return
+	aliases, err := ReadAliases(v.aliasFilePath())
+	if err != nil {
+		return nil, err
+	}
+	var versions []string
+	for _, target := range targetVersions {
+		if IsConstraint(target) {
+			constraint, err := ParseConstraint(target)
+			if err != nil {
+				return nil, err
+			}
+			for _, version := range installed {
+				if constraint.Match(version) {
+					versions = append(versions, version)
+				}
+			}
+			continue
+		}
+		version, err := resolveInstalledVersion(installed, aliases, target)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+	slices.SortFunc(versions, CompareVersions)
+	return slices.Compact(versions), nil
+}
+
+// resolveInstalledVersion 在已安装的版本中解析版本号、别名或 latest：优先精确匹配，
+// 否则按版本号前缀（如 1.22）取最新的匹配版本，1.2 不会匹配 go1.22
+func resolveInstalledVersion(installed []string, aliases map[string]Alias, target string) (string, error) {
+	selector := target
+	if alias, ok := aliases[target]; ok {
+		selector = alias.Target
+	}
+	candidates := slices.Clone(installed)
+	slices.SortFunc(candidates, func(a, b string) int {
+		return CompareVersions(b, a)
+	})
+	if selector == "latest" {
+		if len(candidates) == 0 {
+			return "", fmt.Errorf("%w: %s", ErrNotInstalled, target)
+		}
+		return candidates[0], nil
 	}
-	if err := Uninstall(version, v.sdkFilePath(version)); err != nil {
-		log.Error("Failed to uninstall version:", "error", err)
-		return
+	if slices.Contains(candidates, selector) {
+		return selector, nil
 	}
+	if !strings.HasPrefix(selector, "go") {
+		selector = "go" + selector
+	}
+	for _, version := range candidates {
+		rest, ok := strings.CutPrefix(version, selector)
+		if ok && (rest == "" || !unicode.IsDigit(rune(rest[0]))) {
+			return version, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrNotInstalled, target)
 }
 
-func (v *Version) Ls() {
+func (v *Version) Ls(ctx context.Context) error {
 	vs, err := FetchLocalVersions(v.sdkDir)
 	if err != nil {
-		log.Error("Failed to fetch local versions:", "error", err)
-		return
+		return err
+	}
+	localVersion := v.CurrentVersion()
+	// 列表只用于展示，解析失败的别名不显示
+	versionAliases, _ := v.versionAliases(ctx)
+	if v.output.IsStructured() {
+		items := make([]LsItem, 0, len(vs))
+		for _, version := range vs {
+			item := LsItem{
+				Version: version,
+				Path:    v.SdkFilePath(version),
+				Active:  version == localVersion,
+				Aliases: versionAliases[version],
+			}
+			item.Size, _ = dir.Size(item.Path)
+			if info, err := os.Stat(item.Path); err == nil {
+				item.InstalledAt = info.ModTime()
+			}
+			if item.Aliases == nil {
+				item.Aliases = []string{}
+			}
+			items = append(items, item)
+		}
+		v.printResult(items)
+		return nil
 	}
 	if len(vs) == 0 {
-		log.Info("No local versions found")
-		return
+		v.logger.Info("No local versions found")
+		return nil
 	}
-	// 读取本地版本文件
-	content, _ := os.ReadFile(v.localVersionFilePath)
-	localVersion := string(content)
-	for _, v := range vs {
-		if v == localVersion {
-			fmt.Println("*", v)
+	for _, version := range vs {
+		line := version
+		if names := versionAliases[version]; len(names) > 0 {
+			line = fmt.Sprintf("%s (%s)", version, strings.Join(names, ", "))
+		}
+		if version == localVersion {
+			fmt.Println("*", line)
 		} else {
-			fmt.Println(" ", v)
+			fmt.Println(" ", line)
 		}
 	}
+	return nil
 }
 
-func (v *Version) List(isLatest bool, showNumber int, forceUpdate bool) {
-	originVersions, err := FetchOriginVersions(v.originURL, v.versionFilePath, forceUpdate)
+func (v *Version) AliasSet(ctx context.Context, name, selector string, isFreeze bool) error {
+	if err := ValidateAliasName(name); err != nil {
+		return err
+	}
+	stateLock, err := v.lockState(ctx)
 	if err != nil {
-		log.Error("Failed to fetch origin versions:", "error", err)
-		return
+		return err
 	}
-	if len(originVersions) == 0 {
-		log.Info("No origin versions found")
-		return
+	defer stateLock.Release()
+	aliases, err := ReadAliases(v.aliasFilePath())
+	if err != nil {
+		return err
 	}
-	if isLatest {
-		fmt.Println(originVersions[0].Version)
-		return
+	alias := Alias{Target: selector, Frozen: isFreeze}
+	if isFreeze {
+		version, err := v.ResolveVersion(ctx, selector, false)
+		if err != nil {
+			return err
+		}
+		alias.Target = version
+	}
+	aliases[name] = alias
+	if err := WriteAliases(v.aliasFilePath(), aliases); err != nil {
+		return err
+	}
+	v.logger.Info("set alias", "alias", name, "target", alias.Target, "frozen", alias.Frozen)
+	return nil
+}
+
+func (v *Version) AliasRm(ctx context.Context, name string) error {
+	stateLock, err := v.lockState(ctx)
+	if err != nil {
+		return err
+	}
+	defer stateLock.Release()
+	aliases, err := ReadAliases(v.aliasFilePath())
+	if err != nil {
+		return err
+	}
+	if _, ok := aliases[name]; !ok {
+		return fmt.Errorf("alias %s not found", name)
+	}
+	delete(aliases, name)
+	if err := WriteAliases(v.aliasFilePath(), aliases); err != nil {
+		return err
 	}
-	versions := make([]string, 0, len(originVersions))
-	for index, o := range originVersions {
-		if index >= showNumber {
-			break
+	v.logger.Info("removed alias", "alias", name)
+	return nil
+}
+
+func (v *Version) AliasLs(ctx context.Context) error {
+	aliases, err := ReadAliases(v.aliasFilePath())
+	if err != nil {
+		return err
+	}
+	items := make([]AliasItem, 0, len(aliases))
+	for _, name := range slices.Sorted(maps.Keys(aliases)) {
+		alias := aliases[name]
+		item := AliasItem{Name: name, Target: alias.Target, Frozen: alias.Frozen, Version: alias.Target}
+		if !alias.Frozen {
+			if item.Version, err = v.ResolveVersion(ctx, alias.Target, false); err != nil {
+				item.Version = ""
+			}
+		}
+		items = append(items, item)
+	}
+	if v.printResult(items) {
+		return nil
+	}
+	if len(items) == 0 {
+		v.logger.Info("No aliases found")
+		return nil
+	}
+	for _, item := range items {
+		switch {
+		case item.Frozen:
+			fmt.Printf("%-12s %s (frozen)\n", item.Name, item.Target)
+		case item.Version == "":
+			fmt.Printf("%-12s %s -> unresolved\n", item.Name, item.Target)
+		default:
+			fmt.Printf("%-12s %s -> %s\n", item.Name, item.Target, item.Version)
 		}
-		versions = append(versions, o.Version)
+	}
+	return nil
+}
+
+func (v *Version) History(showNumber int) error {
+	entries, err := ReadHistory(v.historyFilePath())
+	if err != nil {
+		return err
+	}
+	// 最近的记录显示在最前面
+	items := []HistoryItem{}
+	for i := len(entries) - 1; i >= 0 && len(items) < showNumber; i-- {
+		e := entries[i]
+		items = append(items, HistoryItem{Time: e.Time, Version: e.Version, Dir: e.Dir})
+	}
+	if v.printResult(items) {
+		return nil
+	}
+	if len(items) == 0 {
+		v.logger.Info("No history found")
+		return nil
+	}
+	for _, item := range items {
+		fmt.Printf("%s  %-12s %s\n", item.Time.Local().Format(time.DateTime), item.Version, item.Dir)
+	}
+	return nil
+}
+
+func (v *Version) List(ctx context.Context, isLatest bool, showNumber int, forceUpdate bool) error {
+	items, err := v.ListItems(ctx, forceUpdate)
+	if err != nil {
+		return fmt.Errorf("failed to fetch origin versions: %w", err)
+	}
+	if len(items) == 0 {
+		v.logger.Info("No origin versions found")
+		v.printResult(items)
+		return nil
+	}
+	if isLatest {
+		showNumber = 1
+	}
+	items = items[:max(0, min(showNumber, len(items)))]
+	if v.printResult(items) {
+		return nil
+	}
+	versions := make([]string, 0, len(items))
+	for _, item := range items {
+		versions = append(versions, item.Version)
 	}
 	fmt.Println(strings.Join(versions, "\n"))
+	return nil
+}
+
+// ListItems 返回版本来源中的版本及其在当前平台的归档和安装情况，按版本从新到旧排列
+func (v *Version) ListItems(ctx context.Context, forceUpdate bool) ([]ListItem, error) {
+	originVersions, err := v.OriginVersions(ctx, forceUpdate)
+	if err != nil {
+		return nil, err
+	}
+	installed, _ := FetchLocalVersions(v.sdkDir)
+	items := make([]ListItem, 0, len(originVersions))
+	for _, o := range originVersions {
+		item := ListItem{Version: o.Version, Stable: o.Stable, Installed: slices.Contains(installed, o.Version)}
+		if file, ok := findArtifactFile([]OriginVersion{o}, o.Version, runtime.GOOS, runtime.GOARCH); ok {
+			item.Available = true
+			item.Size = int64(file.Size)
+		}
+		items = append(items, item)
+	}
+	return items, nil
 }
 
-func (v *Version) getOriginVersion(targetVersion string, forceUpdate bool) (string, error) {
-	vs, err := FetchOriginVersions(v.originURL, v.versionFilePath, forceUpdate)
+// ResolveVersion 将版本号、别名或 latest 解析为版本来源中的版本，只给出版本号前缀时取最新的匹配版本
+func (v *Version) ResolveVersion(ctx context.Context, targetVersion string, forceUpdate bool) (string, error) {
+	aliases, err := ReadAliases(v.aliasFilePath())
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch origin versions: %v", err)
+		return "", err
+	}
+	if alias, ok := aliases[targetVersion]; ok {
+		v.logger.Info("resolved alias", "alias", targetVersion, "target", alias.Target)
+		targetVersion = alias.Target
+	}
+	vs, err := v.OriginVersions(ctx, forceUpdate)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch origin versions: %w", err)
 	}
 	if len(vs) == 0 {
 		return "", fmt.Errorf("no origin versions found")
@@ -235,11 +742,58 @@ func (v *Version) getOriginVersion(targetVersion string, forceUpdate bool) (stri
 		}
 	}
 	if len(suspiciousVersion) == 0 {
-		return "", fmt.Errorf("version %s not found", targetVersion)
+		return "", fmt.Errorf("%w: %s", ErrVersionNotFound, targetVersion)
 	}
 	return suspiciousVersion[0], nil
 }
 
+// OriginVersions 从版本来源获取版本列表
+func (v *Version) OriginVersions(ctx context.Context, forceUpdate bool) ([]OriginVersion, error) {
+	return v.source.ListVersions(ctx, forceUpdate)
+}
+
+// newSource 按来源名称创建版本来源，共用同一个版本列表缓存文件
+func (v *Version) newSource() Source {
+	cache := originCacheFile{path: v.versionFilePath, ttl: v.originTTL, lockTimeout: v.lockTimeout, logger: v.logger}
+	switch v.sourceName {
+	case SourceGoProxy:
+		return &goProxySource{client: v.httpClient, config: v.goProxy, cache: cache, sumDBDir: filepath.Join(v.cacheDir, "sumdb"), logger: v.logger}
+	case SourceMirror:
+		if u, err := url.Parse(v.downloadURL); err == nil && u.Scheme == "file" {
+			return &localSource{dir: filepath.FromSlash(u.Path)}
+		}
+		return &mirrorSource{client: v.httpClient, baseURL: v.downloadURL, cache: cache}
+	case SourceLocal:
+		return &localSource{dir: v.archiveDir}
+	default:
+		return &goDevSource{client: v.httpClient, originURL: v.originURL, downloadURL: v.downloadURL, mirrors: v.mirrors, cache: cache, logger: v.logger}
+	}
+}
+
+// lockVersion 获取某个版本的锁，保护该版本的缓存归档和 sdk 目录，不同来源的归档共用同一把锁
+func (v *Version) lockVersion(ctx context.Context, version string) (*lock.Lock, error) {
+	return lock.Acquire(ctx, v.versionLockPath(version), v.lockTimeout, v.logger)
+}
+
+// versionLockPath 版本锁文件的路径，放在缓存目录的 locks 子目录下，与归档分开
+func (v *Version) versionLockPath(version string) string {
+	return filepath.Join(v.lockDir(), version+".lock")
+}
+
+func (v *Version) lockDir() string {
+	return filepath.Join(v.cacheDir, "locks")
+}
+
+// lockState 获取状态文件锁，保护 shell 配置、本地版本文件、历史和别名的更新
+func (v *Version) lockState(ctx context.Context) (*lock.Lock, error) {
+	return lock.Acquire(ctx, filepath.Join(v.stateDir(), "state.lock"), v.lockTimeout, v.logger)
+}
+
+// LocalVersions 返回已安装的版本
+func (v *Version) LocalVersions() ([]string, error) {
+	return FetchLocalVersions(v.sdkDir)
+}
+
 func (v *Version) checkLocalVersion(targetVersion string) (bool, error) {
 	vs, err := FetchLocalVersions(v.sdkDir)
 	if err != nil {
@@ -251,15 +805,62 @@ func (v *Version) checkLocalVersion(targetVersion string) (bool, error) {
 	return false, nil
 }
 
-func (v *Version) tarGzFilename(version string) string {
-	return fmt.Sprintf("%s.%s-%s.tar.gz", version, runtime.GOOS, runtime.GOARCH)
+// CurrentVersion 读取本地版本文件中记录的当前版本
+func (v *Version) CurrentVersion() string {
+	content, _ := os.ReadFile(v.localVersionFilePath)
+	return strings.TrimSpace(string(content))
+}
+
+func (v *Version) previousVersion() (string, error) {
+	entries, err := ReadHistory(v.historyFilePath())
+	if err != nil {
+		return "", err
+	}
+	return PreviousVersion(entries, v.CurrentVersion())
+}
+
+// stateDir gvm 状态文件所在目录，与本地版本文件同级
+func (v *Version) stateDir() string {
+	return filepath.Dir(v.localVersionFilePath)
 }
 
-func (v *Version) cacheFilePath(tarGzFilename string) string {
-	return filepath.Join(v.cacheDir, tarGzFilename)
+func (v *Version) historyFilePath() string {
+	return filepath.Join(v.stateDir(), "history.json")
 }
 
-func (v *Version) sdkFilePath(version string) string {
+func (v *Version) aliasFilePath() string {
+	return filepath.Join(v.stateDir(), "aliases.json")
+}
+
+// versionAliases 返回每个版本对应的别名，移动别名按当前解析结果归属；
+// 读取别名文件或解析移动别名失败时返回错误，同时返回其余别名的结果
+func (v *Version) versionAliases(ctx context.Context) (map[string][]string, error) {
+	result := make(map[string][]string)
+	aliases, err := ReadAliases(v.aliasFilePath())
+	if err != nil {
+		return result, err
+	}
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(aliases)) {
+		alias := aliases[name]
+		version := alias.Target
+		if !alias.Frozen {
+			if version, err = v.ResolveVersion(ctx, alias.Target, false); err != nil {
+				errs = append(errs, fmt.Errorf("failed to resolve alias %s (%s): %w", name, alias.Target, err))
+				continue
+			}
+		}
+		result[version] = append(result[version], name)
+	}
+	return result, errors.Join(errs...)
+}
+
+func (v *Version) cacheFilePath(filename string) string {
+	return filepath.Join(v.cacheDir, filename)
+}
+
+// SdkFilePath 版本的安装目录，即切换后的 GOROOT
+func (v *Version) SdkFilePath(version string) string {
 	return filepath.Join(v.sdkDir, version)
 }
 
@@ -287,6 +888,13 @@ func WithDownloadURL(downloadURL string) VersionOption {
 	}
 }
 
+// WithMirrors 指定 go.dev 来源在下载地址不可用时依次尝试的下载地址
+func WithMirrors(mirrors []string) VersionOption {
+	return func(v *Version) {
+		v.mirrors = mirrors
+	}
+}
+
 func WithLocalVersionFilePath(localVersionFilePath string) VersionOption {
 	return func(v *Version) {
 		v.localVersionFilePath = dir.ExpandHomeDir(localVersionFilePath)
@@ -298,3 +906,85 @@ func WithVersionFilePath(versionFilePath string) VersionOption {
 		v.versionFilePath = dir.ExpandHomeDir(versionFilePath)
 	}
 }
+
+func WithOriginTTL(originTTL time.Duration) VersionOption {
+	return func(v *Version) {
+		v.originTTL = originTTL
+	}
+}
+
+func WithLockTimeout(lockTimeout time.Duration) VersionOption {
+	return func(v *Version) {
+		v.lockTimeout = lockTimeout
+	}
+}
+
+func WithNoCache(noCache bool) VersionOption {
+	return func(v *Version) {
+		v.noCache = noCache
+	}
+}
+
+func WithConnections(connections int) VersionOption {
+	return func(v *Version) {
+		v.connections = connections
+	}
+}
+
+func WithHTTPClient(httpClient *http.Client) VersionOption {
+	return func(v *Version) {
+		v.httpClient = httpClient
+	}
+}
+
+func WithSourceName(sourceName string) VersionOption {
+	return func(v *Version) {
+		v.sourceName = sourceName
+	}
+}
+
+// WithSource 直接指定版本来源，优先于 WithSourceName
+func WithSource(source Source) VersionOption {
+	return func(v *Version) {
+		v.source = source
+	}
+}
+
+func WithGoProxyConfig(goProxy GoProxyConfig) VersionOption {
+	return func(v *Version) {
+		v.goProxy = goProxy
+	}
+}
+
+func WithArchiveDir(archiveDir string) VersionOption {
+	return func(v *Version) {
+		v.archiveDir = dir.ExpandHomeDir(archiveDir)
+	}
+}
+
+// WithShell 指定切换版本时写入 GOROOT 的 shell，为空时按环境变量 SHELL 判断
+func WithShell(shell string) VersionOption {
+	return func(v *Version) {
+		v.shell = shell
+	}
+}
+
+// WithNoShellConfig 切换版本时只写入本地版本文件和历史，不修改 shell 配置中的 GOROOT
+func WithNoShellConfig(noShellConfig bool) VersionOption {
+	return func(v *Version) {
+		v.noShellConfig = noShellConfig
+	}
+}
+
+func WithOutput(format output.Format) VersionOption {
+	return func(v *Version) {
+		v.output = format
+	}
+}
+
+// WithLogger 指定日志输出，默认使用 log.Default()
+func WithLogger(logger log.Logger) VersionOption {
+	return func(v *Version) {
+		v.logger = logger
+	}
+}
@@ -22,14 +22,24 @@ THE SOFTWARE.
 package main
 
 import (
+	"context"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/cobra"
 
 	"github.com/aide-cloud/gvm/cmd"
+	"github.com/aide-cloud/gvm/cmd/alias"
+	"github.com/aide-cloud/gvm/cmd/bundle"
+	"github.com/aide-cloud/gvm/cmd/cache"
+	"github.com/aide-cloud/gvm/cmd/config"
+	"github.com/aide-cloud/gvm/cmd/history"
 	"github.com/aide-cloud/gvm/cmd/install"
 	"github.com/aide-cloud/gvm/cmd/list"
 	"github.com/aide-cloud/gvm/cmd/ls"
+	"github.com/aide-cloud/gvm/cmd/mirror"
+	"github.com/aide-cloud/gvm/cmd/prune"
 	"github.com/aide-cloud/gvm/cmd/uninstall"
 	"github.com/aide-cloud/gvm/cmd/use"
 	"github.com/aide-cloud/gvm/pkg/log"
@@ -44,12 +54,29 @@ func main() {
 		install.NewInstallCmd(),
 		uninstall.NewUninstallCmd(),
 		use.NewUseCmd(),
+		history.NewHistoryCmd(),
+		alias.NewAliasCmd(),
+		prune.NewPruneCmd(),
+		cache.NewCacheCmd(),
+		mirror.NewMirrorCmd(),
+		bundle.NewBundleCmd(),
+		config.NewConfigCmd(),
 	}
 
 	rootCmd.AddCommand(commands...)
 
-	if err := rootCmd.Execute(); err != nil {
+	// 收到 Ctrl-C 或 SIGTERM 时取消正在进行的下载和安装，清理未完成的文件后退出；
+	// 取消后恢复默认的信号处理，再次按下 Ctrl-C 会立即结束进程
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	context.AfterFunc(ctx, stop)
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		code := cmd.ExitCode(err)
+		if ctx.Err() != nil {
+			code = cmd.ExitInterrupted
+		}
 		log.Error("Failed to execute root command", "error", err)
-		os.Exit(1)
+		os.Exit(code)
 	}
 }
@@ -0,0 +1,118 @@
+// Package config 读写 YAML 格式的配置文件，键为全局参数名（如 sdk-dir），
+// 值为字符串、布尔、整数或字符串列表
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File 一个配置文件的内容，文件不存在时为空
+type File struct {
+	Path   string
+	values map[string]any
+}
+
+// Load 读取配置文件，文件不存在时返回空配置
+func Load(path string) (*File, error) {
+	f := &File{Path: path, values: make(map[string]any)}
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return f, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %v", path, err)
+	}
+	if err := yaml.Unmarshal(content, &f.values); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %v", path, err)
+	}
+	if f.values == nil {
+		f.values = make(map[string]any)
+	}
+	return f, nil
+}
+
+// Find 从 dir 开始逐级向上查找名为 name 的文件，找不到时返回空
+func Find(dir, name string) string {
+	for {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Get 返回 key 的值，列表返回 []string，其他值转换为字符串
+func (f *File) Get(key string) (any, bool) {
+	value, ok := f.values[key]
+	if !ok || value == nil {
+		return nil, false
+	}
+	if items, ok := value.([]any); ok {
+		list := make([]string, 0, len(items))
+		for _, item := range items {
+			list = append(list, fmt.Sprint(item))
+		}
+		return list, true
+	}
+	return fmt.Sprint(value), true
+}
+
+// Set 设置 key 的值，value 为字符串、布尔、整数或 []string
+func (f *File) Set(key string, value any) {
+	f.values[key] = value
+}
+
+// Unset 删除 key，返回 key 是否存在
+func (f *File) Unset(key string) bool {
+	_, ok := f.values[key]
+	delete(f.values, key)
+	return ok
+}
+
+// Keys 返回按名称排序的所有键
+func (f *File) Keys() []string {
+	keys := make([]string, 0, len(f.values))
+	for key := range f.values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Save 先写入临时文件再重命名为 Path，目录不存在时自动创建
+func (f *File) Save() error {
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %v", err)
+	}
+	var content strings.Builder
+	if len(f.values) > 0 {
+		enc := yaml.NewEncoder(&content)
+		enc.SetIndent(2)
+		if err := enc.Encode(f.values); err != nil {
+			return fmt.Errorf("failed to encode config: %v", err)
+		}
+		if err := enc.Close(); err != nil {
+			return fmt.Errorf("failed to encode config: %v", err)
+		}
+	}
+	partPath := f.Path + ".part"
+	if err := os.WriteFile(partPath, []byte(content.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %v", err)
+	}
+	if err := os.Rename(partPath, f.Path); err != nil {
+		_ = os.Remove(partPath)
+		return fmt.Errorf("failed to save config file: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package download
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// AuthConfig 访问私有镜像的认证配置
+type AuthConfig struct {
+	// MirrorHosts 镜像主机（版本列表地址和下载地址的主机），Token 只发送给这些主机
+	MirrorHosts []string
+	// Token 以 Bearer 方式发送的令牌
+	Token string
+	// Headers 按主机附加的请求头
+	Headers []Header
+	// NetrcPath netrc 文件路径，为空时不读取
+	NetrcPath string
+}
+
+// Header 只发送给指定主机的请求头
+type Header struct {
+	Host  string
+	Name  string
+	Value string
+}
+
+// ParseHeader 解析 HOST=NAME: VALUE 形式的请求头配置
+func ParseHeader(s string) (Header, error) {
+	host, header, found := strings.Cut(s, "=")
+	if !found || host == "" {
+		return Header{}, fmt.Errorf("invalid header %q, expected HOST=NAME: VALUE", s)
+	}
+	name, value, found := strings.Cut(header, ":")
+	if !found || strings.TrimSpace(name) == "" {
+		return Header{}, fmt.Errorf("invalid header %q, expected HOST=NAME: VALUE", s)
+	}
+	return Header{Host: host, Name: strings.TrimSpace(name), Value: strings.TrimSpace(value)}, nil
+}
+
+// DefaultNetrcPath 返回 $NETRC 或 ~/.netrc
+func DefaultNetrcPath() string {
+	if path := os.Getenv("NETRC"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".netrc")
+}
+
+// RedactURL 隐藏 URL 中的密码，用于日志输出
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Redacted()
+}
+
+// RedactHeader 隐藏 HOST=NAME: VALUE 形式请求头的值，无法解析时整体隐藏
+func RedactHeader(s string) string {
+	header, err := ParseHeader(s)
+	if err != nil {
+		return "xxxxx"
+	}
+	return header.Host + "=" + header.Name + ": xxxxx"
+}
+
+type netrcEntry struct {
+	machine  string
+	login    string
+	password string
+}
+
+// parseNetrc 解析 netrc 文件，文件不存在时返回空列表；machine 为空表示 default 条目
+func parseNetrc(path string) ([]netrcEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open the netrc file: %v", err)
+	}
+	defer file.Close()
+
+	var (
+		entries []netrcEntry
+		current *netrcEntry
+	)
+	scanner := bufio.NewScanner(file)
+	scanner.Split(bufio.ScanWords)
+	next := func() string {
+		if scanner.Scan() {
+			return scanner.Text()
+		}
+		return ""
+	}
+	for scanner.Scan() {
+		switch scanner.Text() {
+		case "machine":
+			entries = append(entries, netrcEntry{machine: next()})
+			current = &entries[len(entries)-1]
+		case "default":
+			entries = append(entries, netrcEntry{})
+			current = &entries[len(entries)-1]
+		case "login":
+			if login := next(); current != nil {
+				current.login = login
+			}
+		case "password":
+			if password := next(); current != nil {
+				current.password = password
+			}
+		case "account":
+			next()
+		case "macdef":
+			// 宏定义在 netrc 中以空行结束，按单词扫描时无法识别，忽略其后的内容
+			return entries, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read the netrc file: %v", err)
+	}
+	return entries, nil
+}
+
+// authTransport 为发往镜像主机的请求附加认证信息
+type authTransport struct {
+	base    http.RoundTripper
+	config  AuthConfig
+	entries []netrcEntry
+}
+
+func newAuthTransport(base http.RoundTripper, config AuthConfig) (http.RoundTripper, error) {
+	t := &authTransport{base: base, config: config}
+	if config.NetrcPath != "" {
+		entries, err := parseNetrc(config.NetrcPath)
+		if err != nil {
+			return nil, err
+		}
+		t.entries = entries
+	}
+	return t, nil
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for _, h := range t.config.Headers {
+		if matchHost(h.Host, req.URL) {
+			req.Header.Set(h.Name, h.Value)
+		}
+	}
+	if req.Header.Get("Authorization") == "" && t.config.Token != "" && t.isMirror(req.URL) {
+		req.Header.Set("Authorization", "Bearer "+t.config.Token)
+	}
+	if req.Header.Get("Authorization") == "" && req.URL.User == nil {
+		if entry, ok := t.lookupNetrc(req.URL); ok {
+			req.SetBasicAuth(entry.login, entry.password)
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+func (t *authTransport) isMirror(u *url.URL) bool {
+	return slices.ContainsFunc(t.config.MirrorHosts, func(host string) bool { return matchHost(host, u) })
+}
+
+// lookupNetrc 查找主机对应的 netrc 条目；default 条目只用于镜像主机，
+// 避免把凭据发送给源站或下载过程中跳转到的其他主机
+func (t *authTransport) lookupNetrc(u *url.URL) (netrcEntry, bool) {
+	for _, entry := range t.entries {
+		if entry.machine == u.Hostname() {
+			return entry, true
+		}
+	}
+	if !t.isMirror(u) {
+		return netrcEntry{}, false
+	}
+	for _, entry := range t.entries {
+		if entry.machine == "" {
+			return entry, true
+		}
+	}
+	return netrcEntry{}, false
+}
+
+// matchHost 主机配置可以带端口，不带端口时匹配任意端口
+func matchHost(host string, u *url.URL) bool {
+	return host == u.Host || host == u.Hostname()
+}
@@ -0,0 +1,134 @@
+package download
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingServer 记录每个请求收到的 Authorization 和 X-Token 请求头
+type recordingServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	headers []http.Header
+}
+
+func newRecordingServer(t *testing.T) *recordingServer {
+	t.Helper()
+	s := &recordingServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.headers = append(s.headers, r.Header.Clone())
+		s.mu.Unlock()
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *recordingServer) last() http.Header {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.headers[len(s.headers)-1]
+}
+
+func (s *recordingServer) hostPort() string {
+	return strings.TrimPrefix(s.URL, "http://")
+}
+
+func TestAuthTransportSendsCredentialsOnlyToMatchingHosts(t *testing.T) {
+	mirror := newRecordingServer(t)
+	other := newRecordingServer(t)
+
+	netrcPath := filepath.Join(t.TempDir(), "netrc")
+	if err := os.WriteFile(netrcPath, []byte("default login user password secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		config    AuthConfig
+		wantMatch string
+	}{
+		{
+			name:      "netrc default",
+			config:    AuthConfig{MirrorHosts: []string{mirror.hostPort()}, NetrcPath: netrcPath},
+			wantMatch: "Basic ",
+		},
+		{
+			name:      "token",
+			config:    AuthConfig{MirrorHosts: []string{mirror.hostPort()}, Token: "t0ken"},
+			wantMatch: "Bearer t0ken",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := newAuthTransport(http.DefaultTransport, tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			client := &http.Client{Transport: transport}
+			for _, srv := range []*recordingServer{mirror, other} {
+				body, err := Open(context.Background(), client, srv.URL)
+				if err != nil {
+					t.Fatal(err)
+				}
+				body.Close()
+			}
+			if got := mirror.last().Get("Authorization"); !strings.HasPrefix(got, tt.wantMatch) {
+				t.Errorf("mirror Authorization = %q, want %q", got, tt.wantMatch)
+			}
+			if got := other.last().Get("Authorization"); got != "" {
+				t.Errorf("credentials leaked to another host: %q", got)
+			}
+		})
+	}
+
+	t.Run("header", func(t *testing.T) {
+		transport, err := newAuthTransport(http.DefaultTransport, AuthConfig{
+			Headers: []Header{{Host: other.hostPort(), Name: "X-Token", Value: "abc"}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		client := &http.Client{Transport: transport}
+		for _, srv := range []*recordingServer{mirror, other} {
+			body, err := Open(context.Background(), client, srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body.Close()
+		}
+		if got := other.last().Get("X-Token"); got != "abc" {
+			t.Errorf("X-Token = %q, want abc", got)
+		}
+		if got := mirror.last().Get("X-Token"); got != "" {
+			t.Errorf("header leaked to another host: %q", got)
+		}
+	})
+}
+
+func TestLookupNetrcMachine(t *testing.T) {
+	netrcPath := filepath.Join(t.TempDir(), "netrc")
+	content := "machine example.com login a password b\ndefault login c password d\n"
+	if err := os.WriteFile(netrcPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	transport, err := newAuthTransport(http.DefaultTransport, AuthConfig{NetrcPath: netrcPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := transport.(*authTransport)
+	req := httptest.NewRequest(http.MethodGet, "https://example.com:8443/go.tar.gz", nil)
+	if entry, ok := tr.lookupNetrc(req.URL); !ok || entry.login != "a" {
+		t.Errorf("machine entry = %+v, %v", entry, ok)
+	}
+	req = httptest.NewRequest(http.MethodGet, "https://dl.google.com/go.tar.gz", nil)
+	if entry, ok := tr.lookupNetrc(req.URL); ok {
+		t.Errorf("default entry used for a host that is not a mirror: %+v", entry)
+	}
+}
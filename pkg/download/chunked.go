@@ -0,0 +1,130 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FetchFileChunked 服务端支持 Range 请求时把文件分成 connections 段并发下载，
+// 否则退回到单连接下载。任意一段失败或 ctx 取消时中止其余分段并删除已写入的部分内容
+func FetchFileChunked(ctx context.Context, client *http.Client, url, destPath string, connections int) (err error) {
+	if connections <= 1 {
+		return FetchFile(ctx, client, url, destPath)
+	}
+	size, ok := probeRangeSupport(ctx, client, url)
+	if !ok || size < int64(connections) {
+		return FetchFile(ctx, client, url, destPath)
+	}
+
+	out, err := os.Create(destPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		out.Close()
+		if err != nil {
+			_ = os.Remove(destPath)
+		}
+	}()
+	if err := out.Truncate(size); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	chunkSize := (size + int64(connections) - 1) / int64(connections)
+	errs := make([]error, connections)
+	var wg sync.WaitGroup
+	for i := range connections {
+		start := int64(i) * chunkSize
+		end := min(start+chunkSize, size) - 1
+		if start > end {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if errs[i] = fetchRange(ctx, client, url, out, start, end); errs[i] != nil {
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+	// 优先返回导致取消的分段错误，而不是被取消的其他分段的错误
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return err
+		}
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// probeRangeSupport 请求第一个字节判断服务端是否支持 Range，并返回文件总大小
+func probeRangeSupport(ctx context.Context, client *http.Client, url string) (int64, bool) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, false
+	}
+	req.Header.Set("Range", "bytes=0-0")
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		return 0, false
+	}
+	// Content-Range: bytes 0-0/12345
+	_, total, found := strings.Cut(resp.Header.Get("Content-Range"), "/")
+	if !found {
+		return 0, false
+	}
+	size, err := strconv.ParseInt(total, 10, 64)
+	if err != nil || size <= 0 {
+		return 0, false
+	}
+	return size, true
+}
+
+// fetchRange 下载 [start, end] 区间的内容并写入文件的对应位置
+func fetchRange(ctx context.Context, client *http.Client, url string, out io.WriterAt, start, end int64) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("unexpected status %s for range %d-%d", resp.Status, start, end)
+	}
+	// 服务端返回的区间与请求不一致时写入的位置会错位
+	if got, want := resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-%d/", start, end); !strings.HasPrefix(got, want) {
+		return fmt.Errorf("unexpected content range %q for range %d-%d", got, start, end)
+	}
+	want := end - start + 1
+	n, err := io.Copy(io.NewOffsetWriter(out, start), io.LimitReader(resp.Body, want))
+	if err != nil {
+		return err
+	}
+	if n != want {
+		return fmt.Errorf("short read for range %d-%d: got %d bytes", start, end, n)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testContent(size int) []byte {
+	content := make([]byte, size)
+	r := rand.New(rand.NewPCG(1, 2))
+	for i := range content {
+		content[i] = byte(r.Uint32())
+	}
+	return content
+}
+
+func serveContent(content []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "go.tar.gz", time.Time{}, bytes.NewReader(content))
+	}
+}
+
+func TestFetchFileChunked(t *testing.T) {
+	content := testContent(100_003)
+	var ranged atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			ranged.Add(1)
+		}
+		serveContent(content)(w, r)
+	}))
+	defer srv.Close()
+
+	destPath := filepath.Join(t.TempDir(), "go.tar.gz")
+	if err := FetchFileChunked(context.Background(), srv.Client(), srv.URL, destPath, 4); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(destPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Fatal("downloaded content differs")
+	}
+	// 1 次探测加 4 个分段
+	if n := ranged.Load(); n != 5 {
+		t.Errorf("got %d range requests, want 5", n)
+	}
+}
+
+func TestFetchFileChunkedIgnoredRange(t *testing.T) {
+	content := testContent(10_000)
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write(content)
+	}))
+	defer srv.Close()
+
+	destPath := filepath.Join(t.TempDir(), "go.tar.gz")
+	if err := FetchFileChunked(context.Background(), srv.Client(), srv.URL, destPath, 4); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(destPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Fatal("downloaded content differs")
+	}
+	// 1 次探测加 1 次完整下载
+	if n := requests.Load(); n != 2 {
+		t.Errorf("got %d requests, want a single stream after the probe", n)
+	}
+}
+
+func TestFetchFileChunkedBadPartialContent(t *testing.T) {
+	content := testContent(10_000)
+	tests := []struct {
+		name    string
+		handler func(w http.ResponseWriter, start, end int64)
+	}{
+		{
+			name: "short body",
+			handler: func(w http.ResponseWriter, start, end int64) {
+				w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(content)))
+				w.WriteHeader(http.StatusPartialContent)
+				_, _ = w.Write(content[start:end])
+			},
+		},
+		{
+			name: "wrong range",
+			handler: func(w http.ResponseWriter, start, end int64) {
+				w.Header().Set("Content-Range", fmt.Sprintf("bytes 0-%d/%d", end-start, len(content)))
+				w.WriteHeader(http.StatusPartialContent)
+				_, _ = w.Write(content[:end-start+1])
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var start, end int64
+				if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-%d", &start, &end); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				if start == 0 && end == 0 {
+					serveContent(content)(w, r)
+					return
+				}
+				tt.handler(w, start, end)
+			}))
+			defer srv.Close()
+
+			destPath := filepath.Join(t.TempDir(), "go.tar.gz")
+			if err := FetchFileChunked(context.Background(), srv.Client(), srv.URL, destPath, 4); err == nil {
+				t.Fatal("expected an error")
+			}
+			if _, err := os.Stat(destPath); !os.IsNotExist(err) {
+				t.Errorf("partial file was left behind: %v", err)
+			}
+		})
+	}
+}
+
+// throttledWriter 模拟每个连接带宽受限的服务端
+type throttledWriter struct {
+	w        io.Writer
+	chunk    int
+	perChunk time.Duration
+}
+
+func (t throttledWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := min(len(p), t.chunk)
+		time.Sleep(t.perChunk)
+		if _, err := t.w.Write(p[:n]); err != nil {
+			return written, err
+		}
+		written += n
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// BenchmarkFetchFileChunked 每个连接限速约 16 MB/s 时比较不同并发数的下载耗时
+func BenchmarkFetchFileChunked(b *testing.B) {
+	content := testContent(4 << 20)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tw := throttledWriter{w: w, chunk: 16 << 10, perChunk: time.Millisecond}
+		http.ServeContent(responseWriter{ResponseWriter: w, w: tw}, r, "go.tar.gz", time.Time{}, bytes.NewReader(content))
+	}))
+	defer srv.Close()
+
+	for _, connections := range []int{1, 4, 8} {
+		b.Run(fmt.Sprintf("connections=%d", connections), func(b *testing.B) {
+			destPath := filepath.Join(b.TempDir(), "go.tar.gz")
+			b.SetBytes(int64(len(content)))
+			for b.Loop() {
+				if err := FetchFileChunked(context.Background(), srv.Client(), srv.URL, destPath, connections); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	w io.Writer
+}
+
+func (r responseWriter) Write(p []byte) (int, error) {
+	return r.w.Write(p)
+}
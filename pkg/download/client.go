@@ -0,0 +1,77 @@
+package download
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/aide-cloud/gvm/pkg/log"
+)
+
+// ClientConfig 下载使用的 HTTP 客户端配置
+type ClientConfig struct {
+	// ProxyURL 代理地址，为空时使用 HTTP_PROXY/HTTPS_PROXY/NO_PROXY 环境变量
+	ProxyURL string
+	// CAFile 额外信任的 CA 证书（PEM），与系统证书一起使用
+	CAFile string
+	// CertFile、KeyFile 双向 TLS 使用的客户端证书和私钥
+	CertFile string
+	KeyFile  string
+	// InsecureSkipVerify 跳过服务端证书校验，仅用于排查问题
+	InsecureSkipVerify bool
+	// Auth 私有镜像的认证配置
+	Auth AuthConfig
+}
+
+// NewClient 根据配置创建 HTTP 客户端，gvm 的所有请求都应使用该客户端
+func NewClient(cfg ClientConfig) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if cfg.ProxyURL != "" {
+		proxyURL, err := url.Parse(cfg.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url: %v", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.CAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the ca file: %v", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in the ca file %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		if cfg.CertFile == "" || cfg.KeyFile == "" {
+			return nil, fmt.Errorf("both the client cert and the client key are required")
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if cfg.InsecureSkipVerify {
+		log.Warn("!!! TLS certificate verification is DISABLED, downloads can be intercepted and tampered with !!!")
+		tlsConfig.InsecureSkipVerify = true
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	authTransport, err := newAuthTransport(transport, cfg.Auth)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: authTransport}, nil
+}
@@ -3,6 +3,10 @@ package download
 import (
 	"archive/tar"
 	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
@@ -10,27 +14,103 @@ import (
 	"strings"
 )
 
-// DownloadFile 下载文件
-func FetchFile(url, destPath string) error {
-	resp, err := http.Get(url)
+// StatusError 服务端返回了非预期的状态码
+type StatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return "unexpected status " + e.Status
+}
+
+// Open 发起 GET 请求并返回响应内容，状态码不是 200 时返回错误。ctx 取消时请求和读取响应都会中止
+func Open(ctx context.Context, client *http.Client, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	return resp.Body, nil
+}
+
+// FetchFile 下载文件，失败或 ctx 取消时删除已写入的部分内容
+func FetchFile(ctx context.Context, client *http.Client, url, destPath string) error {
+	body, err := Open(ctx, client, url)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
+	defer body.Close()
+	return SaveFile(ctx, body, destPath)
+}
 
-	// 创建目标文件
+// SaveFile 把 r 的内容写入 destPath，失败或 ctx 取消时删除已写入的部分内容
+func SaveFile(ctx context.Context, r io.Reader, destPath string) error {
 	out, err := os.Create(destPath)
 	if err != nil {
 		return err
 	}
-	defer out.Close()
-
-	_, err = io.Copy(out, resp.Body)
+	_, err = Copy(ctx, out, r)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(destPath)
+	}
 	return err
 }
 
-// ExtractTarGz 解压 tar.gz 文件
-func ExtractGoSdkTarGzFile(srcPath, destPath string) error {
+// Copy 与 io.Copy 相同，但每次读取前检查 ctx，取消后返回 ctx.Err()
+func Copy(ctx context.Context, dst io.Writer, src io.Reader) (int64, error) {
+	return io.Copy(dst, contextReader{ctx: ctx, r: src})
+}
+
+// contextReader ctx 取消后读取返回 ctx.Err()，用于中止本地文件的复制和解压
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// ExtractGoSdkTarGzStream 边读取边解压 tar.gz 内容，同时计算读取内容的 SHA-256；
+// teePath 非空时把读取的内容同时写入该文件。ctx 取消时中止并返回 ctx.Err()
+func ExtractGoSdkTarGzStream(ctx context.Context, r io.Reader, destPath, teePath string) (string, error) {
+	hash := sha256.New()
+	writers := []io.Writer{hash}
+	if teePath != "" {
+		out, err := os.Create(teePath)
+		if err != nil {
+			return "", err
+		}
+		defer out.Close()
+		writers = append(writers, out)
+	}
+	reader := io.TeeReader(contextReader{ctx: ctx, r: r}, io.MultiWriter(writers...))
+	if err := extractGoSdkTarGz(reader, destPath); err != nil {
+		return "", err
+	}
+	// 读完 tar 结束标记之后剩余的内容，保证摘要覆盖完整的下载内容
+	if _, err := io.Copy(io.Discard, reader); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// ExtractTarGz 解压 tar.gz 文件，ctx 取消时中止并返回 ctx.Err()
+func ExtractGoSdkTarGzFile(ctx context.Context, srcPath, destPath string) error {
 	// 打开 tar.gz 文件
 	file, err := os.Open(srcPath)
 	if err != nil {
@@ -38,7 +118,11 @@ func ExtractGoSdkTarGzFile(srcPath, destPath string) error {
 	}
 	defer file.Close()
 
-	gzReader, err := gzip.NewReader(file)
+	return extractGoSdkTarGz(contextReader{ctx: ctx, r: file}, destPath)
+}
+
+func extractGoSdkTarGz(r io.Reader, destPath string) error {
+	gzReader, err := gzip.NewReader(r)
 	if err != nil {
 		return err
 	}
@@ -64,13 +148,18 @@ func ExtractGoSdkTarGzFile(srcPath, destPath string) error {
 			continue
 		}
 
-		target := filepath.Join(destPath, relativePath)
+		target, err := joinWithin(destPath, relativePath)
+		if err != nil {
+			return fmt.Errorf("invalid file path in tar: %s", header.Name)
+		}
 
 		switch header.Typeflag {
 		case tar.TypeDir: // 目录
 			if err := os.MkdirAll(target, 0755); err != nil {
 				return err
 			}
+		case tar.TypeXGlobalHeader: // pax 全局头，不对应文件
+			continue
 		case tar.TypeReg: // 文件
 			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
 				return err
@@ -84,7 +173,34 @@ func ExtractGoSdkTarGzFile(srcPath, destPath string) error {
 			if err != nil {
 				return err
 			}
+		default:
+			// Go 的 sdk 归档只包含目录和普通文件，链接和设备文件可能指向目标目录之外
+			return fmt.Errorf("unsupported entry type %q in tar: %s", header.Typeflag, header.Name)
 		}
 	}
 	return nil
 }
+
+// joinWithin 把归档中的相对路径拼接到 destPath 下，路径逃逸出 destPath 时返回错误
+func joinWithin(destPath, name string) (string, error) {
+	target := filepath.Join(destPath, name)
+	if !strings.HasPrefix(target, filepath.Clean(destPath)+string(os.PathSeparator)) {
+		return "", fmt.Errorf("path %s escapes %s", name, destPath)
+	}
+	return target, nil
+}
+
+// SHA256File 计算文件的 SHA-256 摘要
+func SHA256File(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,91 @@
+package download
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type tarEntry struct {
+	name     string
+	typeflag byte
+	body     string
+	linkname string
+}
+
+func buildTarGz(t *testing.T, entries []tarEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Typeflag: e.typeflag, Mode: 0644, Size: int64(len(e.body)), Linkname: e.linkname}
+		if e.typeflag != tar.TypeReg {
+			header.Size = 0
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if e.typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(e.body)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractGoSdkTarGzStream(t *testing.T) {
+	archive := buildTarGz(t, []tarEntry{
+		{name: "go/", typeflag: tar.TypeDir},
+		{name: "go/bin/", typeflag: tar.TypeDir},
+		{name: "go/bin/go", typeflag: tar.TypeReg, body: "binary"},
+		{name: "go/VERSION", typeflag: tar.TypeReg, body: "go1.22.1"},
+	})
+	dest := filepath.Join(t.TempDir(), "sdk")
+	if _, err := ExtractGoSdkTarGzStream(context.Background(), bytes.NewReader(archive), dest, ""); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filepath.Join(dest, "bin", "go"))
+	if err != nil || string(content) != "binary" {
+		t.Fatalf("bin/go = %q, %v", content, err)
+	}
+}
+
+func TestExtractGoSdkTarGzRejectsUnsafeEntries(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry tarEntry
+	}{
+		{name: "parent directory", entry: tarEntry{name: "go/../../escaped", typeflag: tar.TypeReg, body: "x"}},
+		{name: "outside the go directory", entry: tarEntry{name: "../escaped", typeflag: tar.TypeReg, body: "x"}},
+		{name: "symlink", entry: tarEntry{name: "go/link", typeflag: tar.TypeSymlink, linkname: "/etc/passwd"}},
+		{name: "hard link", entry: tarEntry{name: "go/link", typeflag: tar.TypeLink, linkname: "go/VERSION"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			dest := filepath.Join(root, "staging", "sdk")
+			archive := buildTarGz(t, []tarEntry{tt.entry})
+			if _, err := ExtractGoSdkTarGzStream(context.Background(), bytes.NewReader(archive), dest, ""); err == nil {
+				t.Fatal("expected an error")
+			}
+			if _, err := os.Lstat(filepath.Join(root, "escaped")); !os.IsNotExist(err) {
+				t.Errorf("entry was written outside the destination: %v", err)
+			}
+			if _, err := os.Lstat(filepath.Join(root, "staging", "escaped")); !os.IsNotExist(err) {
+				t.Errorf("entry was written outside the destination: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package download
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ExtractModuleZipFile 解压 Go 模块 zip 文件（如 golang.org/toolchain 的工具链模块），
+// 去掉 "<module>@<version>/" 前缀。ctx 取消时中止并返回 ctx.Err()
+func ExtractModuleZipFile(ctx context.Context, srcPath, destPath string) error {
+	zipReader, err := zip.OpenReader(srcPath)
+	if err != nil {
+		return err
+	}
+	defer zipReader.Close()
+
+	for _, f := range zipReader.File {
+		relativePath, ok := stripModulePrefix(f.Name)
+		if !ok || relativePath == "" {
+			continue
+		}
+		// 防止 zip 中的路径逃逸出目标目录
+		target, err := joinWithin(destPath, relativePath)
+		if err != nil {
+			return fmt.Errorf("invalid file path in zip: %s", f.Name)
+		}
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := extractZipEntry(ctx, f, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractZipEntry(ctx context.Context, f *zip.File, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	outFile, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	_, err = Copy(ctx, outFile, rc)
+	outFile.Close()
+	return err
+}
+
+// stripModulePrefix 去掉模块 zip 中每个文件名的 "<module>@<version>/" 前缀
+func stripModulePrefix(name string) (string, bool) {
+	at := strings.Index(name, "@")
+	if at < 0 {
+		return "", false
+	}
+	slash := strings.Index(name[at:], "/")
+	if slash < 0 {
+		return "", false
+	}
+	return name[at+slash+1:], true
+}
+
+// HashModuleZip 计算模块 zip 文件的 h1 哈希，与 go.sum 和校验和数据库中的格式一致
+func HashModuleZip(path string) (string, error) {
+	zipReader, err := zip.OpenReader(path)
+	if err != nil {
+		return "", err
+	}
+	defer zipReader.Close()
+
+	files := slices.Clone(zipReader.File)
+	slices.SortFunc(files, func(a, b *zip.File) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	summary := sha256.New()
+	for _, f := range files {
+		if strings.Contains(f.Name, "\n") {
+			return "", fmt.Errorf("invalid file name in zip: %q", f.Name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return "", err
+		}
+		hash := sha256.New()
+		_, err = io.Copy(hash, rc)
+		rc.Close()
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(summary, "%x  %s\n", hash.Sum(nil), f.Name)
+	}
+	return "h1:" + base64.StdEncoding.EncodeToString(summary.Sum(nil)), nil
+}
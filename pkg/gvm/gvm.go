@@ -0,0 +1,277 @@
+// Package gvm 提供嵌入 gvm 的 Go 接口，用于解析、安装、卸载、列出和切换 Go 版本。
+// Manager 不向标准输出打印任何内容，日志和安装进度通过注入的 Logger 和回调输出
+package gvm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"slices"
+
+	"github.com/aide-cloud/gvm/internal/version"
+)
+
+// 可以用 errors.Is 判断的错误类型
+var (
+	ErrVersionNotFound  = version.ErrVersionNotFound
+	ErrNotInstalled     = version.ErrNotInstalled
+	ErrChecksumMismatch = version.ErrChecksumMismatch
+	ErrUnsupportedShell = version.ErrUnsupportedShell
+	ErrActiveVersion    = version.ErrActiveVersion
+)
+
+// ProgressFunc 安装进度回调，args 为 slog 形式的键值对
+type ProgressFunc func(version, stage string, args ...any)
+
+// Release 版本来源中可安装的版本
+type Release struct {
+	Version string
+	Stable  bool
+	// Available 当前平台是否有归档
+	Available bool
+	// Size 当前平台归档的字节数，版本来源没有提供时为 0
+	Size int64
+}
+
+// Installation 已安装的版本
+type Installation struct {
+	Version string
+	// Path 安装目录，即切换后的 GOROOT
+	Path   string
+	Active bool
+}
+
+// Activation 切换版本的结果
+type Activation struct {
+	Version  string
+	Previous string
+	GOROOT   string
+	// ShellConfig 写入了 GOROOT 的 shell 配置文件，不修改 shell 配置时为空
+	ShellConfig string
+}
+
+type options struct {
+	root        string
+	httpClient  *http.Client
+	logger      *slog.Logger
+	progress    ProgressFunc
+	originURL   string
+	downloadURL string
+	shell       string
+	// shellConfig 为 nil 时按是否指定了 root 决定
+	shellConfig *bool
+}
+
+type Option func(*options)
+
+// WithRoot 把 sdk、缓存和状态文件都放在 root 下：root/sdk、root/cache、root/versions.json 和 root/version。
+// 指定 root 后 Activate 默认不修改 root 之外的 shell 配置，需要时使用 WithShell 或 WithShellConfig(true)。
+// 不指定时与 gvm 命令行使用相同的默认目录
+func WithRoot(root string) Option {
+	return func(o *options) {
+		o.root = root
+	}
+}
+
+// WithShell 指定 Activate 写入 GOROOT 的 shell（bash、zsh 或其路径），为空时按环境变量 SHELL 判断，
+// 指定后即使设置了 WithRoot 也会修改该 shell 的配置文件
+func WithShell(shell string) Option {
+	return func(o *options) {
+		o.shell = shell
+	}
+}
+
+// WithShellConfig 指定 Activate 是否修改 shell 配置中的 GOROOT，为 false 时只更新 root 下的版本文件和历史。
+// 默认在没有 WithRoot 时修改，与 gvm use 一致
+func WithShellConfig(enabled bool) Option {
+	return func(o *options) {
+		o.shellConfig = &enabled
+	}
+}
+
+// WithHTTPClient 指定获取版本列表和下载归档使用的 http.Client
+func WithHTTPClient(client *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = client
+	}
+}
+
+// WithLogger 指定日志输出，默认丢弃所有日志
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithProgress 指定安装进度回调
+func WithProgress(progress ProgressFunc) Option {
+	return func(o *options) {
+		o.progress = progress
+	}
+}
+
+// WithOriginURL 指定 go.dev 格式的版本列表地址
+func WithOriginURL(originURL string) Option {
+	return func(o *options) {
+		o.originURL = originURL
+	}
+}
+
+// WithDownloadURL 指定归档下载地址
+func WithDownloadURL(downloadURL string) Option {
+	return func(o *options) {
+		o.downloadURL = downloadURL
+	}
+}
+
+// Manager 管理本机安装的 Go 版本，可以在多个 goroutine 中使用，
+// 同一版本的安装和卸载以及当前版本的切换通过文件锁串行执行
+type Manager struct {
+	v        *version.Version
+	progress ProgressFunc
+}
+
+func New(opts ...Option) *Manager {
+	o := options{
+		httpClient: http.DefaultClient,
+		logger:     slog.New(slog.DiscardHandler),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	versionOpts := []version.VersionOption{
+		version.WithHTTPClient(o.httpClient),
+		version.WithLogger(o.logger),
+	}
+	if o.root != "" {
+		versionOpts = append(versionOpts,
+			version.WithSdkDir(filepath.Join(o.root, "sdk")),
+			version.WithCacheDir(filepath.Join(o.root, "cache")),
+			version.WithVersionFilePath(filepath.Join(o.root, "versions.json")),
+			version.WithLocalVersionFilePath(filepath.Join(o.root, "version")),
+		)
+	}
+	shellConfig := o.root == "" || o.shell != ""
+	if o.shellConfig != nil {
+		shellConfig = *o.shellConfig
+	}
+	versionOpts = append(versionOpts, version.WithShell(o.shell), version.WithNoShellConfig(!shellConfig))
+	if o.originURL != "" {
+		versionOpts = append(versionOpts, version.WithOriginURL(o.originURL))
+	}
+	if o.downloadURL != "" {
+		versionOpts = append(versionOpts, version.WithDownloadURL(o.downloadURL))
+	}
+	return &Manager{v: version.NewVersion(versionOpts...), progress: o.progress}
+}
+
+// Resolve 将版本号、别名或 latest 解析为具体版本，如 1.22 解析为 go1.22.x 中最新的版本
+func (m *Manager) Resolve(ctx context.Context, selector string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return m.v.ResolveVersion(ctx, selector, false)
+}
+
+// List 返回版本来源中的版本，按版本从新到旧排列
+func (m *Manager) List(ctx context.Context) ([]Release, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	items, err := m.v.ListItems(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	releases := make([]Release, 0, len(items))
+	for _, item := range items {
+		releases = append(releases, Release{Version: item.Version, Stable: item.Stable, Available: item.Available, Size: item.Size})
+	}
+	// 镜像和 GOPROXY 返回的列表不一定有序
+	slices.SortStableFunc(releases, func(a, b Release) int {
+		return version.CompareVersions(b.Version, a.Version)
+	})
+	return releases, nil
+}
+
+// Installed 返回已安装的版本
+func (m *Manager) Installed(ctx context.Context) ([]Installation, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	versions, err := m.v.LocalVersions()
+	if err != nil {
+		return nil, err
+	}
+	current := m.v.CurrentVersion()
+	installations := make([]Installation, 0, len(versions))
+	for _, v := range versions {
+		installations = append(installations, Installation{Version: v, Path: m.v.SdkFilePath(v), Active: v == current})
+	}
+	return installations, nil
+}
+
+// Install 解析并安装版本，已安装且 force 为 false 时直接返回。
+// ctx 被取消时中止下载和解压并返回 ctx.Err()，不会留下未完成的安装目录和缓存文件
+func (m *Manager) Install(ctx context.Context, selector string, force bool) (Installation, error) {
+	v, err := m.Resolve(ctx, selector)
+	if err != nil {
+		return Installation{}, err
+	}
+	if _, err := m.v.InstallVersion(ctx, v, force, m.versionProgress(v)); err != nil {
+		return Installation{}, fmt.Errorf("failed to install %s: %w", v, err)
+	}
+	return Installation{Version: v, Path: m.v.SdkFilePath(v), Active: v == m.v.CurrentVersion()}, nil
+}
+
+// Uninstall 卸载已安装的版本，当前使用中的版本需要先切换到其他版本，否则返回 ErrActiveVersion
+func (m *Manager) Uninstall(ctx context.Context, selector string) error {
+	v, err := m.installedVersion(ctx, selector)
+	if err != nil {
+		return err
+	}
+	if v == m.v.CurrentVersion() {
+		return fmt.Errorf("%w %s", ErrActiveVersion, v)
+	}
+	return m.v.UninstallVersion(ctx, v)
+}
+
+// Activate 将已安装的版本设为当前版本，写入版本文件并记录切换历史，
+// 按 WithShellConfig 和 WithShell 在 shell 配置中设置 GOROOT
+func (m *Manager) Activate(ctx context.Context, selector string) (Activation, error) {
+	v, err := m.installedVersion(ctx, selector)
+	if err != nil {
+		return Activation{}, err
+	}
+	result, err := m.v.ActivateVersion(ctx, v)
+	if err != nil {
+		return Activation{}, err
+	}
+	return Activation{Version: result.Version, Previous: result.Previous, GOROOT: result.GOROOT, ShellConfig: result.ShellConfig}, nil
+}
+
+// installedVersion 解析版本并确认已安装
+func (m *Manager) installedVersion(ctx context.Context, selector string) (string, error) {
+	v, err := m.Resolve(ctx, selector)
+	if err != nil {
+		return "", err
+	}
+	installed, err := m.v.LocalVersions()
+	if err != nil {
+		return "", err
+	}
+	if !slices.Contains(installed, v) {
+		return "", fmt.Errorf("%w: %s", ErrNotInstalled, v)
+	}
+	return v, nil
+}
+
+// versionProgress 把安装进度转发给注入的回调
+func (m *Manager) versionProgress(v string) version.ProgressFunc {
+	return func(stage string, args ...any) {
+		if m.progress != nil {
+			m.progress(v, stage, args...)
+		}
+	}
+}
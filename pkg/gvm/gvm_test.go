@@ -0,0 +1,245 @@
+package gvm
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// buildSdkArchive 返回只包含 VERSION、bin 和 pkg/tool 的最小 sdk 归档
+func buildSdkArchive(t *testing.T, version string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	files := []struct{ name, body string }{
+		{name: "go/VERSION", body: version},
+		{name: "go/bin/go", body: "#!/bin/sh\n"},
+		{name: "go/pkg/tool/linux_amd64/compile", body: "#!/bin/sh\n"},
+	}
+	for _, f := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Typeflag: tar.TypeReg, Mode: 0755, Size: int64(len(f.body))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(f.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// newTestOrigin 返回 go.dev 格式的版本来源，提供 versions 在当前平台的归档
+func newTestOrigin(t *testing.T, versions ...string) []Option {
+	t.Helper()
+	type file struct {
+		Filename string `json:"filename"`
+		OS       string `json:"os"`
+		Arch     string `json:"arch"`
+		Version  string `json:"version"`
+		SHA256   string `json:"sha256"`
+		Size     int    `json:"size"`
+		Kind     string `json:"kind"`
+	}
+	type release struct {
+		Version string `json:"version"`
+		Stable  bool   `json:"stable"`
+		Files   []file `json:"files"`
+	}
+	archives := make(map[string][]byte)
+	var releases []release
+	for _, version := range versions {
+		content := buildSdkArchive(t, version)
+		sum := sha256.Sum256(content)
+		filename := fmt.Sprintf("%s.%s-%s.tar.gz", version, runtime.GOOS, runtime.GOARCH)
+		archives[filename] = content
+		releases = append(releases, release{Version: version, Stable: true, Files: []file{{
+			Filename: filename, OS: runtime.GOOS, Arch: runtime.GOARCH, Version: version,
+			SHA256: hex.EncodeToString(sum[:]), Size: len(content), Kind: "archive",
+		}}})
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			json.NewEncoder(w).Encode(releases)
+			return
+		}
+		content, ok := archives[strings.TrimPrefix(r.URL.Path, "/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(content)
+	}))
+	t.Cleanup(srv.Close)
+	return []Option{WithOriginURL(srv.URL + "/?mode=json&include=all"), WithDownloadURL(srv.URL + "/")}
+}
+
+// newTestHome 把 HOME 和 SHELL 指向临时目录，返回其中的 .bashrc 内容
+func newTestHome(t *testing.T) (string, string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SHELL", "/bin/bash")
+	content := "alias ll='ls -l'\n"
+	if err := os.WriteFile(filepath.Join(home, ".bashrc"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return home, content
+}
+
+func TestNewWithRoot(t *testing.T) {
+	newTestHome(t)
+	root := t.TempDir()
+	m := New(append(newTestOrigin(t, "go1.22.1"), WithRoot(root))...)
+	installed, err := m.Installed(context.Background())
+	if err != nil || len(installed) != 0 {
+		t.Fatalf("Installed = %v, %v, want nothing", installed, err)
+	}
+	inst, err := m.Install(context.Background(), "latest", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(root, "sdk", "go1.22.1"); inst.Path != want {
+		t.Errorf("Path = %s, want %s", inst.Path, want)
+	}
+	for _, path := range []string{filepath.Join(root, "cache"), filepath.Join(root, "versions.json")} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected %s under the root: %v", path, err)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	m := New(append(newTestOrigin(t, "go1.21.5", "go1.22.1"), WithRoot(t.TempDir()))...)
+	releases, err := m.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var versions []string
+	for _, r := range releases {
+		if !r.Available || !r.Stable || r.Size == 0 {
+			t.Errorf("release %+v, want a stable release available on this platform", r)
+		}
+		versions = append(versions, r.Version)
+	}
+	if want := []string{"go1.22.1", "go1.21.5"}; !slices.Equal(versions, want) {
+		t.Errorf("List = %v, want %v", versions, want)
+	}
+}
+
+func TestInstall(t *testing.T) {
+	var stages []string
+	m := New(append(newTestOrigin(t, "go1.21.5", "go1.22.1"), WithRoot(t.TempDir()), WithProgress(func(version, stage string, args ...any) {
+		stages = append(stages, version+" "+stage)
+	}))...)
+	inst, err := m.Install(context.Background(), "1.21", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inst.Version != "go1.21.5" || inst.Active {
+		t.Errorf("Install = %+v, want inactive go1.21.5", inst)
+	}
+	content, err := os.ReadFile(filepath.Join(inst.Path, "VERSION"))
+	if err != nil || string(content) != "go1.21.5" {
+		t.Fatalf("VERSION = %q, %v", content, err)
+	}
+	if len(stages) == 0 {
+		t.Error("progress callback was not called")
+	}
+	if _, err := m.Install(context.Background(), "1.23", false); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("Install(1.23) = %v, want ErrVersionNotFound", err)
+	}
+}
+
+func TestActivate(t *testing.T) {
+	home, bashrc := newTestHome(t)
+	root := t.TempDir()
+	origin := newTestOrigin(t, "go1.21.5", "go1.22.1")
+	m := New(append(origin, WithRoot(root))...)
+	for _, selector := range []string{"1.21", "1.22"} {
+		if _, err := m.Install(context.Background(), selector, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := m.Activate(context.Background(), "1.23"); !errors.Is(err, ErrNotInstalled) && !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("Activate(1.23) = %v, want a not installed error", err)
+	}
+
+	activation, err := m.Activate(context.Background(), "1.22")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if activation.Version != "go1.22.1" || activation.GOROOT != filepath.Join(root, "sdk", "go1.22.1") || activation.ShellConfig != "" {
+		t.Errorf("Activate = %+v", activation)
+	}
+	if content, _ := os.ReadFile(filepath.Join(home, ".bashrc")); string(content) != bashrc {
+		t.Errorf(".bashrc was modified with WithRoot:\n%s", content)
+	}
+	installed, err := m.Installed(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, inst := range installed {
+		if inst.Active != (inst.Version == "go1.22.1") {
+			t.Errorf("installation %+v has the wrong active state", inst)
+		}
+	}
+
+	m = New(append(origin, WithRoot(root), WithShell("bash"))...)
+	activation, err = m.Activate(context.Background(), "go1.21.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if activation.Previous != "go1.22.1" || activation.ShellConfig != filepath.Join(home, ".bashrc") {
+		t.Errorf("Activate with WithShell = %+v", activation)
+	}
+	content, _ := os.ReadFile(filepath.Join(home, ".bashrc"))
+	if !strings.Contains(string(content), `export GOROOT="`+filepath.Join(root, "sdk", "go1.21.5")+`"`) {
+		t.Errorf(".bashrc does not set GOROOT:\n%s", content)
+	}
+}
+
+func TestUninstallActiveVersion(t *testing.T) {
+	newTestHome(t)
+	m := New(append(newTestOrigin(t, "go1.21.5", "go1.22.1"), WithRoot(t.TempDir()))...)
+	for _, selector := range []string{"1.21", "1.22"} {
+		if _, err := m.Install(context.Background(), selector, false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := m.Activate(context.Background(), "go1.22.1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Uninstall(context.Background(), "go1.22.1"); !errors.Is(err, ErrActiveVersion) {
+		t.Fatalf("Uninstall(active) = %v, want ErrActiveVersion", err)
+	}
+	if err := m.Uninstall(context.Background(), "go1.21.5"); err != nil {
+		t.Fatal(err)
+	}
+	installed, err := m.Installed(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(installed) != 1 || installed[0].Version != "go1.22.1" || !installed[0].Active {
+		t.Errorf("Installed = %+v, want only the active go1.22.1", installed)
+	}
+}
@@ -0,0 +1,125 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aide-cloud/gvm/pkg/log"
+)
+
+// retryInterval 锁被占用时的重试间隔
+const retryInterval = 100 * time.Millisecond
+
+var (
+	// errLocked 锁已被其他进程持有
+	errLocked = errors.New("locked by another process")
+	// ErrTimeout 等待其他进程释放锁超时
+	ErrTimeout = errors.New("timed out waiting for lock")
+)
+
+// Lock 基于文件的跨进程咨询锁，锁文件中记录持有者的 PID
+type Lock struct {
+	file *os.File
+}
+
+// Acquire 获取 path 对应的锁，超过 timeout 仍未获取到时返回带有持有者 PID 的错误，ctx 取消时停止等待。
+// 需要等待时通过 logger 提示，logger 为 nil 时不输出
+func Acquire(ctx context.Context, path string, timeout time.Duration, logger log.Logger) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create the lock directory: %v", err)
+	}
+	if !supported && logger != nil {
+		logger.Debug("file locks are not supported on this platform, concurrent gvm processes are not serialized", "path", path)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the lock file: %v", err)
+	}
+	deadline := time.Now().Add(timeout)
+	waiting := false
+	for {
+		err := tryLock(file)
+		if err == nil {
+			if isCurrent(file, path) {
+				break
+			}
+			// 等待期间锁文件被 Remove 删除，持有的是已删除文件的锁，重新打开后再加锁
+			_ = unlock(file)
+			file.Close()
+			if file, err = os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644); err != nil {
+				return nil, fmt.Errorf("failed to open the lock file: %v", err)
+			}
+			continue
+		}
+		if !errors.Is(err, errLocked) {
+			file.Close()
+			return nil, fmt.Errorf("failed to lock %s: %v", path, err)
+		}
+		if !time.Now().Before(deadline) {
+			file.Close()
+			return nil, fmt.Errorf("%w %s after %s, held by pid %s", ErrTimeout, path, timeout, holder(path))
+		}
+		if !waiting && logger != nil {
+			logger.Info("waiting for lock", "path", path, "pid", holder(path))
+		}
+		waiting = true
+		select {
+		case <-ctx.Done():
+			file.Close()
+			return nil, ctx.Err()
+		case <-time.After(retryInterval):
+		}
+	}
+
+	// 记录持有者 PID，供等待者输出提示
+	if err := file.Truncate(0); err == nil {
+		_, _ = file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	return &Lock{file: file}, nil
+}
+
+// Release 释放锁，锁文件保留以免与其他进程的加锁产生竞争
+func (l *Lock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	defer l.file.Close()
+	return unlock(l.file)
+}
+
+// Remove 删除锁文件并释放锁，用于清理不再需要的锁。
+// 正在等待该锁的进程获取到锁后会发现文件已被删除，重新创建锁文件后再加锁
+func (l *Lock) Remove() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	err := os.Remove(l.file.Name())
+	if releaseErr := l.Release(); err == nil {
+		err = releaseErr
+	}
+	return err
+}
+
+// isCurrent 判断已打开的锁文件是否仍是 path 指向的文件
+func isCurrent(file *os.File, path string) bool {
+	opened, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	current, err := os.Stat(path)
+	return err == nil && os.SameFile(opened, current)
+}
+
+func holder(path string) string {
+	content, err := os.ReadFile(path)
+	if err != nil || len(strings.TrimSpace(string(content))) == 0 {
+		return "unknown"
+	}
+	return strings.TrimSpace(string(content))
+}
@@ -0,0 +1,94 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package lock
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAcquireWaitsAndTimesOut(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "go1.22.1.lock")
+	held, err := Acquire(context.Background(), path, time.Second, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	_, err = Acquire(context.Background(), path, 300*time.Millisecond, logger)
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("got %v, want ErrTimeout", err)
+	}
+	if !strings.Contains(err.Error(), strconv.Itoa(os.Getpid())) {
+		t.Errorf("error %q does not name the holder", err)
+	}
+	if got := strings.Count(buf.String(), "waiting for lock"); got != 1 {
+		t.Errorf("logged the wait %d times, want once: %s", got, buf.String())
+	}
+
+	if err := held.Release(); err != nil {
+		t.Fatal(err)
+	}
+	l, err := Acquire(context.Background(), path, time.Second, logger)
+	if err != nil {
+		t.Fatalf("lock not released: %v", err)
+	}
+	_ = l.Release()
+}
+
+func TestAcquireCancelled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.lock")
+	held, err := Acquire(context.Background(), path, time.Second, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer held.Release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := Acquire(ctx, path, time.Minute, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want the context error", err)
+	}
+}
+
+func TestRemoveWhileWaiting(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "go1.22.1.lock")
+	held, err := Acquire(context.Background(), path, time.Second, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type result struct {
+		l   *Lock
+		err error
+	}
+	acquired := make(chan result)
+	go func() {
+		l, err := Acquire(context.Background(), path, 5*time.Second, nil)
+		acquired <- result{l, err}
+	}()
+	time.Sleep(3 * retryInterval)
+	if err := held.Remove(); err != nil {
+		t.Fatal(err)
+	}
+	waiter := <-acquired
+	if waiter.err != nil {
+		t.Fatal(waiter.err)
+	}
+	defer waiter.l.Release()
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("lock file was not recreated: %v", err)
+	}
+	// 等待者持有的必须是新建的锁文件，否则其他进程可以同时获取到锁
+	if _, err := Acquire(context.Background(), path, 0, nil); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("got %v, want the lock to be held by the waiter", err)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package lock
+
+import "os"
+
+// supported 为 false 表示没有 flock，Acquire 总是立即成功，不做跨进程互斥：
+// 在 Windows 等平台上同时运行多个 gvm 进程安装同一版本或修改状态文件时可能互相覆盖
+const supported = false
+
+func tryLock(file *os.File) error {
+	return nil
+}
+
+func unlock(file *os.File) error {
+	return nil
+}
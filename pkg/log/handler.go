@@ -0,0 +1,106 @@
+package log
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// textHandler 输出 key=value 形式的文本日志。compact 为 true 时省略时间，Info 及以下省略级别，
+// 便于在终端阅读；否则与 slog 默认输出一致：时间 级别 消息 字段
+type textHandler struct {
+	mu      *sync.Mutex
+	w       io.Writer
+	level   slog.Leveler
+	compact bool
+	// attrs 通过 WithAttrs 绑定的字段，已格式化
+	attrs  []byte
+	prefix string
+}
+
+func newTextHandler(w io.Writer, level slog.Leveler, compact bool) *textHandler {
+	return &textHandler{mu: &sync.Mutex{}, w: w, level: level, compact: compact}
+}
+
+func (h *textHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *textHandler) Handle(_ context.Context, r slog.Record) error {
+	var buf []byte
+	if !h.compact {
+		if !r.Time.IsZero() {
+			buf = r.Time.AppendFormat(buf, "2006/01/02 15:04:05 ")
+		}
+		buf = append(buf, r.Level.String()...)
+		buf = append(buf, ' ')
+	} else if r.Level > slog.LevelInfo {
+		buf = append(buf, r.Level.String()...)
+		buf = append(buf, ' ')
+	}
+	buf = append(buf, r.Message...)
+	buf = append(buf, h.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		buf = appendAttr(buf, h.prefix, a)
+		return true
+	})
+	buf = append(buf, '\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(buf)
+	return err
+}
+
+func (h *textHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = h.attrs[:len(h.attrs):len(h.attrs)]
+	for _, a := range attrs {
+		h2.attrs = appendAttr(h2.attrs, h.prefix, a)
+	}
+	return &h2
+}
+
+func (h *textHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+func appendAttr(buf []byte, prefix string, a slog.Attr) []byte {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return buf
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			buf = appendAttr(buf, prefix, ga)
+		}
+		return buf
+	}
+	buf = append(buf, ' ')
+	buf = append(buf, prefix...)
+	buf = append(buf, a.Key...)
+	buf = append(buf, '=')
+	s := a.Value.String()
+	if needsQuoting(s) {
+		return strconv.AppendQuote(buf, s)
+	}
+	return append(buf, s...)
+}
+
+func needsQuoting(s string) bool {
+	return s == "" || strings.IndexFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '=' || r == '"' || !unicode.IsPrint(r)
+	}) >= 0
+}
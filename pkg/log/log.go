@@ -1,33 +1,132 @@
 package log
 
 import (
+	"fmt"
+	"io"
 	"log/slog"
+	"os"
 	"sync"
 )
 
-var isPrintEnable = true
-var once sync.Once
+// 日志格式
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
 
-func SetPrintEnable(enable bool) {
-	once.Do(func() {
-		isPrintEnable = enable
-	})
+// Config 日志配置，可以多次调用 Setup 修改
+type Config struct {
+	// Level 低于该级别的日志被丢弃，默认 Info
+	Level slog.Level
+	// Format 为 text 或 json，默认 text
+	Format string
+	// File 追加写入的日志文件，为空时输出到标准错误
+	File string
 }
 
-func Info(msg string, args ...any) {
-	if isPrintEnable {
-		slog.Info(msg, args...)
+var (
+	mu      sync.Mutex
+	level   = new(slog.LevelVar)
+	std     = slog.New(newTextHandler(os.Stderr, level, true))
+	logFile *os.File
+)
+
+// Setup 按配置替换包级函数和 Default 使用的日志输出，替换前打开的日志文件被关闭。
+// text 格式输出到终端时只显示消息和字段，Debug 级别或写入日志文件时带上时间和级别
+func Setup(c Config) error {
+	var w io.Writer = os.Stderr
+	var file *os.File
+	if c.File != "" {
+		f, err := os.OpenFile(c.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %v", err)
+		}
+		w, file = f, f
 	}
+	var handler slog.Handler
+	switch c.Format {
+	case "", FormatText:
+		handler = newTextHandler(w, level, c.File == "" && c.Level > slog.LevelDebug)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})
+	default:
+		if file != nil {
+			_ = file.Close()
+		}
+		return fmt.Errorf("unsupported log format %q, must be one of %s, %s", c.Format, FormatText, FormatJSON)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	level.Set(c.Level)
+	std = slog.New(handler)
+	if logFile != nil {
+		_ = logFile.Close()
+	}
+	logFile = file
+	return nil
 }
 
-func Error(msg string, args ...any) {
-	if isPrintEnable {
-		slog.Error(msg, args...)
+// Logger 日志接口，*slog.Logger 满足该接口，便于嵌入方注入自己的日志
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+// Default 返回通过包级函数输出的 Logger，受 Setup 控制
+func Default() Logger {
+	return defaultLogger{}
+}
+
+type defaultLogger struct{}
+
+func (defaultLogger) Debug(msg string, args ...any) { Debug(msg, args...) }
+func (defaultLogger) Info(msg string, args ...any)  { Info(msg, args...) }
+func (defaultLogger) Warn(msg string, args ...any)  { Warn(msg, args...) }
+func (defaultLogger) Error(msg string, args ...any) { Error(msg, args...) }
+
+// With 返回每条日志都带上 args 字段的 Logger，用于给一次操作的日志加上版本、镜像等字段
+func With(l Logger, args ...any) Logger {
+	if sl, ok := l.(*slog.Logger); ok {
+		return sl.With(args...)
 	}
+	return withLogger{l: l, args: args}
+}
+
+type withLogger struct {
+	l    Logger
+	args []any
+}
+
+func (w withLogger) Debug(msg string, args ...any) { w.l.Debug(msg, w.with(args)...) }
+func (w withLogger) Info(msg string, args ...any)  { w.l.Info(msg, w.with(args)...) }
+func (w withLogger) Warn(msg string, args ...any)  { w.l.Warn(msg, w.with(args)...) }
+func (w withLogger) Error(msg string, args ...any) { w.l.Error(msg, w.with(args)...) }
+
+func (w withLogger) with(args []any) []any {
+	return append(w.args[:len(w.args):len(w.args)], args...)
+}
+
+func logger() *slog.Logger {
+	mu.Lock()
+	defer mu.Unlock()
+	return std
+}
+
+func Debug(msg string, args ...any) {
+	logger().Debug(msg, args...)
+}
+
+func Info(msg string, args ...any) {
+	logger().Info(msg, args...)
+}
+
+func Error(msg string, args ...any) {
+	logger().Error(msg, args...)
 }
 
 func Warn(msg string, args ...any) {
-	if isPrintEnable {
-		slog.Warn(msg, args...)
-	}
+	logger().Warn(msg, args...)
 }
@@ -0,0 +1,56 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format 命令结果的输出格式
+type Format string
+
+const (
+	// Table 人类可读的文本输出
+	Table Format = "table"
+	JSON  Format = "json"
+	YAML  Format = "yaml"
+)
+
+var formats = []Format{Table, JSON, YAML}
+
+// ParseFormat 解析输出格式
+func ParseFormat(s string) (Format, error) {
+	format := Format(strings.ToLower(s))
+	if !slices.Contains(formats, format) {
+		return "", fmt.Errorf("unsupported output format %q, must be one of table, json, yaml", s)
+	}
+	return format, nil
+}
+
+// IsStructured 是否为 JSON/YAML 等机器可读格式
+func (f Format) IsStructured() bool {
+	return f == JSON || f == YAML
+}
+
+// Print 按 JSON 或 YAML 格式输出 v，表格格式由调用方自行输出
+func Print(w io.Writer, format Format, v any) error {
+	switch format {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case YAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("format %s is not structured", format)
+	}
+}
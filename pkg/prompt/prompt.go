@@ -0,0 +1,41 @@
+package prompt
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Confirm 在终端询问用户是否继续，只有输入 y 或 yes 时返回 true，ctx 取消时返回 false。
+// 提示输出到标准错误，避免混入标准输出中的结构化结果
+func Confirm(ctx context.Context, message string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N]: ", message)
+	answers := make(chan string, 1)
+	go func() {
+		answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && answer == "" {
+			fmt.Fprintln(os.Stderr)
+		}
+		answers <- answer
+	}()
+	var answer string
+	select {
+	case <-ctx.Done():
+		fmt.Fprintln(os.Stderr)
+		return false
+	case answer = <-answers:
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// IsTerminal 标准输入是否为终端，不是终端时无法由用户确认
+func IsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}